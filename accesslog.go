@@ -0,0 +1,115 @@
+package leopard
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/volix-dev/leopard/defaultlogger"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// AccessLogConfig configures the AccessLog middleware.
+type AccessLogConfig struct {
+	// Format is the format of a dedicated access logger (logfmt or json).
+	// When empty, the entries are written to the request logger in the format of the app.
+	Format string
+
+	// Output is where the dedicated access logger writes to, defaults to stdout.
+	Output io.Writer
+
+	// Skip decides if a request is not logged, for example health checks.
+	Skip func(c ContextInterface) bool
+}
+
+// AccessLog logs every request with its method, route, status code, latency and the size of the body.
+// Server errors are logged as error, client errors as warning and all other requests as info.
+//
+// Errors returned by the rest of the chain are passed to the error handler here,
+// so the logged status code is the one that was sent to the client.
+func AccessLog(config AccessLogConfig) HandlerFunc {
+	var accessLogger LoggerInterface
+
+	if config.Format != "" {
+		logger := defaultlogger.New()
+		output := config.Output
+
+		if output == nil {
+			output = os.Stdout
+		}
+
+		if err := logger.SetFormat(config.Format); err != nil {
+			panic(err)
+		}
+
+		logger.SetOutput(output)
+		accessLogger = logger
+	}
+
+	return func(c ContextInterface) error {
+		if config.Skip != nil && config.Skip(c) {
+			return c.Next()
+		}
+
+		start := time.Now()
+
+		defer func() {
+			if r := recover(); r != nil {
+				logRequest(c, accessLogger, start, http.StatusInternalServerError)
+				panic(r)
+			}
+		}()
+
+		if err := c.Next(); err != nil {
+			_ = c.Error(err)
+		}
+
+		logRequest(c, accessLogger, start, c.Response().StatusCode())
+
+		return nil
+	}
+}
+
+// requestFields gets the log fields describing the request: the method, path and route.
+func requestFields(r *http.Request) Fields {
+	fields := Fields{
+		"method": r.Method,
+		"path":   r.URL.Path,
+	}
+
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			fields["route"] = template
+		}
+
+		if name := route.GetName(); name != "" {
+			fields["route_name"] = name
+		}
+	}
+
+	return fields
+}
+
+// logRequest writes the access log entry of the request.
+func logRequest(c ContextInterface, accessLogger LoggerInterface, start time.Time, status int) {
+	logger := c.Logger()
+
+	if accessLogger != nil {
+		logger = accessLogger.WithContext(c.Request().Context()).WithFields(requestFields(c.Request()))
+	}
+
+	logger = logger.WithFields(Fields{
+		"status":     status,
+		"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+		"bytes":      c.Response().BytesWritten(),
+	})
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		logger.Error("request")
+	case status >= http.StatusBadRequest:
+		logger.Warning("request")
+	default:
+		logger.Info("request")
+	}
+}
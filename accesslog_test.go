@@ -0,0 +1,73 @@
+package leopard_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	a := leopardtest.New(t)
+	output := &bytes.Buffer{}
+
+	a.Use(leopard.AccessLog(leopard.AccessLogConfig{
+		Format: "json",
+		Output: output,
+	}))
+
+	a.GET("/users/{id}", func(c leopard.ContextInterface) error {
+		c.AddLogFields(leopard.Fields{"user": "bob"})
+
+		if c.GetParam("id") != "1" {
+			return leopard.ErrNotFound
+		}
+
+		_, err := c.WriteString("bob")
+
+		return err
+	}, "users.show")
+
+	client := a.Test()
+
+	client.GET("/users/1").Expect(t).Status(200)
+	client.GET("/users/2").Expect(t).Status(404)
+
+	decoder := json.NewDecoder(output)
+
+	for _, expected := range []struct {
+		status float64
+		level  string
+		bytes  float64
+	}{
+		{200, "info", 3},
+		{404, "warning", -1},
+	} {
+		var entry map[string]interface{}
+
+		if err := decoder.Decode(&entry); err != nil {
+			t.Fatalf("expected an access log entry: %v", err)
+		}
+
+		if entry["status"] != expected.status || entry["level"] != expected.level {
+			t.Errorf("expected status %v at level %s, got %v", expected.status, expected.level, entry)
+		}
+
+		if entry["user"] != "bob" {
+			t.Errorf("expected the fields added by the handler to be logged, got %v", entry)
+		}
+
+		if entry["route"] != "/users/{id}" || entry["route_name"] != "users.show" || entry["method"] != "GET" {
+			t.Errorf("expected the route to be logged, got %v", entry)
+		}
+
+		if expected.bytes >= 0 && entry["bytes"] != expected.bytes {
+			t.Errorf("expected %v bytes, got %v", expected.bytes, entry["bytes"])
+		}
+
+		if _, ok := entry["latency_ms"]; !ok {
+			t.Errorf("expected the latency to be logged, got %v", entry)
+		}
+	}
+}
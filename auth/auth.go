@@ -0,0 +1,125 @@
+// Package auth authenticates the users of requests with guards, like sessions, bearer tokens, basic auth and API keys.
+//
+// Authenticate runs the guards for every request and makes the user available with Context.User,
+// Required and Guest protect routes:
+//
+//	app.Use(leopard.Sessions(leopard.SessionConfig{}))
+//	app.Use(auth.Authenticate(auth.SessionGuard(users)))
+//
+//	app.Group("/account", func(group leopard.RouteGroup) {
+//		group.GET("/", showAccount)
+//	}, auth.Required())
+package auth
+
+import (
+	"context"
+	"github.com/volix-dev/leopard"
+	"net/http"
+)
+
+// Errors returned by the middleware, they are responded with a 401 and a 403.
+var (
+	ErrUnauthenticated = leopard.NewHTTPError(http.StatusUnauthorized, "authentication required")
+	ErrAuthenticated   = leopard.NewHTTPError(http.StatusForbidden, "already authenticated")
+)
+
+// UserProvider loads the users for the guards.
+type UserProvider interface {
+	// FindByID gets the user with the ID, it returns nil when there is none.
+	FindByID(ctx context.Context, id string) (leopard.User, error)
+
+	// FindByCredentials gets the user with the username when the password matches, it returns nil otherwise.
+	// Use CheckPassword to compare the password with a stored hash.
+	FindByCredentials(ctx context.Context, username string, password string) (leopard.User, error)
+}
+
+// TokenLookup gets the user of a token, like an API key, it returns nil when the token is not valid.
+type TokenLookup func(ctx context.Context, token string) (leopard.User, error)
+
+// Guard authenticates requests.
+type Guard interface {
+	// Authenticate gets the user of the request, it returns nil when the request has no valid credentials.
+	// Errors are only returned when the user could not be loaded, like when the database is down.
+	Authenticate(c leopard.ContextInterface) (leopard.User, error)
+}
+
+// Challenger is implemented by guards that tell the client how to authenticate, like the WWW-Authenticate header of basic auth.
+type Challenger interface {
+	Challenge(c leopard.ContextInterface)
+}
+
+// GuardFunc is a function that is used as Guard.
+type GuardFunc func(c leopard.ContextInterface) (leopard.User, error)
+
+func (f GuardFunc) Authenticate(c leopard.ContextInterface) (leopard.User, error) {
+	return f(c)
+}
+
+// authenticate runs the guards until one of them finds a user.
+func authenticate(c leopard.ContextInterface, guards []Guard) error {
+	if c.IsAuthenticated() {
+		return nil
+	}
+
+	for _, guard := range guards {
+		user, err := guard.Authenticate(c)
+
+		if err != nil {
+			return err
+		}
+
+		if user != nil {
+			c.SetUser(user)
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Authenticate sets the user of the request with the first guard that finds one.
+// Requests without a user are not rejected, use Required for that.
+func Authenticate(guards ...Guard) leopard.HandlerFunc {
+	return func(c leopard.ContextInterface) error {
+		if err := authenticate(c, guards); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+// Required rejects requests without a user with ErrUnauthenticated.
+// The guards authenticate requests that were not authenticated yet, for example by Authenticate,
+// and tell the client how to authenticate when they implement Challenger.
+func Required(guards ...Guard) leopard.HandlerFunc {
+	return func(c leopard.ContextInterface) error {
+		if err := authenticate(c, guards); err != nil {
+			return err
+		}
+
+		if !c.IsAuthenticated() {
+			for _, guard := range guards {
+				if challenger, ok := guard.(Challenger); ok {
+					challenger.Challenge(c)
+				}
+			}
+
+			return ErrUnauthenticated
+		}
+
+		return c.Next()
+	}
+}
+
+// Guest rejects requests with a user with ErrAuthenticated, for pages like the login form.
+func Guest() leopard.HandlerFunc {
+	return func(c leopard.ContextInterface) error {
+		if c.IsAuthenticated() {
+			return ErrAuthenticated
+		}
+
+		return c.Next()
+	}
+}
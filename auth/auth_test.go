@@ -0,0 +1,202 @@
+package auth_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/auth"
+	"github.com/volix-dev/leopard/leopardtest"
+	"net/url"
+	"testing"
+)
+
+type user struct {
+	id       string
+	name     string
+	password string
+}
+
+func (u *user) AuthID() string {
+	return u.id
+}
+
+type users map[string]*user
+
+func (u users) FindByID(ctx context.Context, id string) (leopard.User, error) {
+	if found, ok := u[id]; ok {
+		return found, nil
+	}
+
+	return nil, nil
+}
+
+func (u users) FindByCredentials(ctx context.Context, username string, password string) (leopard.User, error) {
+	for _, found := range u {
+		if found.name == username && auth.CheckPassword(found.password, password) {
+			return found, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (u users) FindByToken(ctx context.Context, token string) (leopard.User, error) {
+	if token == "token-of-bob" {
+		return u["1"], nil
+	}
+
+	return nil, nil
+}
+
+func authApp(t *testing.T) *leopardtest.App {
+	hash, err := auth.HashPassword("secret")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := users{"1": {id: "1", name: "bob", password: hash}}
+	a := leopardtest.New(t)
+
+	a.Use(leopard.Sessions(leopard.SessionConfig{Secret: "secret"}))
+	a.Use(auth.Authenticate(
+		auth.SessionGuard(provider),
+		auth.BearerGuard(provider.FindByToken),
+		auth.APIKeyGuard("", provider.FindByToken),
+	))
+
+	a.POST("/login", func(c leopard.ContextInterface) error {
+		found, err := auth.Attempt(c, provider, c.ReadFormValue("username"), c.ReadFormValue("password"), c.ReadFormValue("remember") != "")
+
+		if err == nil && found == nil {
+			err = auth.ErrUnauthenticated
+		}
+
+		return err
+	}, auth.Guest())
+
+	a.POST("/logout", func(c leopard.ContextInterface) error {
+		return auth.Logout(c)
+	})
+
+	a.GET("/session", func(c leopard.ContextInterface) error {
+		return c.Session().Set("visited", true)
+	})
+
+	a.GET("/me", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString(c.User().(*user).name)
+
+		return err
+	}, auth.Required())
+
+	a.Group("/admin", func(group leopard.RouteGroup) {
+		group.GET("/", func(c leopard.ContextInterface) {})
+	}, auth.Required(auth.BasicGuard(provider, "admin")))
+
+	return a
+}
+
+func TestLogin(t *testing.T) {
+	a := authApp(t)
+	client := a.Test()
+
+	client.GET("/me").Expect(t).Status(401)
+	client.GET("/session").Expect(t).Status(200)
+
+	before := client.Cookies("/")[0].Value
+
+	client.POST("/login").WithForm(url.Values{"username": {"bob"}, "password": {"wrong"}}).Expect(t).Status(401)
+	client.POST("/login").WithForm(url.Values{"username": {"bob"}, "password": {"secret"}}).Expect(t).Status(200)
+
+	if after := client.Cookies("/")[0].Value; after == before {
+		t.Error("expected the session to get a new ID on login")
+	}
+
+	client.GET("/me").Expect(t).Status(200).BodyEquals("bob")
+	client.POST("/login").WithForm(url.Values{"username": {"bob"}, "password": {"secret"}}).Expect(t).Status(403)
+
+	// The session ID from before the login does not work.
+	a.Test().GET("/me").WithCookie("leopard_session", before).Expect(t).Status(401)
+
+	client.POST("/logout").Expect(t).Status(200)
+	client.GET("/me").Expect(t).Status(401)
+}
+
+func TestRemember(t *testing.T) {
+	a := authApp(t)
+	client := a.Test()
+
+	client.POST("/login").WithForm(url.Values{"username": {"bob"}, "password": {"secret"}, "remember": {"1"}}).Expect(t).Status(200)
+
+	var token string
+
+	for _, cookie := range client.Cookies("/") {
+		if cookie.Name == auth.RememberCookie {
+			token = cookie.Value
+		}
+	}
+
+	if token == "" {
+		t.Fatal("expected a remember-me cookie")
+	}
+
+	// A new session is logged in with the token, the token is replaced.
+	response := a.Test().GET("/me").WithCookie(auth.RememberCookie, token).Expect(t).Status(200).BodyEquals("bob")
+	rotated := false
+
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == auth.RememberCookie && cookie.Value != "" && cookie.Value != token {
+			rotated = true
+		}
+	}
+
+	if !rotated {
+		t.Error("expected the remember-me token to be replaced")
+	}
+
+	a.Test().GET("/me").WithCookie(auth.RememberCookie, token).Expect(t).Status(401)
+}
+
+func TestTokenGuards(t *testing.T) {
+	client := authApp(t).Test()
+
+	client.GET("/me").WithHeader("Authorization", "Bearer token-of-bob").Expect(t).Status(200).BodyEquals("bob")
+	client.GET("/me").WithHeader("Authorization", "Bearer wrong").Expect(t).Status(401)
+	client.GET("/me").WithHeader("X-API-Key", "token-of-bob").Expect(t).Status(200).BodyEquals("bob")
+}
+
+func TestBasicGuard(t *testing.T) {
+	client := authApp(t).Test()
+	credentials := base64.StdEncoding.EncodeToString([]byte("bob:secret"))
+
+	client.GET("/admin").Expect(t).
+		Status(401).
+		Header("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+
+	client.GET("/admin").WithHeader("Authorization", "Basic "+credentials).Expect(t).Status(200)
+}
+
+func TestUserLogFields(t *testing.T) {
+	provider := users{"1": {id: "1", name: "bob"}}
+	output := &bytes.Buffer{}
+	a := leopardtest.New(t)
+
+	a.Use(leopard.AccessLog(leopard.AccessLogConfig{Format: "json", Output: output}))
+	a.Use(auth.Authenticate(auth.BearerGuard(provider.FindByToken)))
+
+	a.GET("/me", func(c leopard.ContextInterface) {})
+
+	a.Test().GET("/me").WithHeader("Authorization", "Bearer token-of-bob").Expect(t).Status(200)
+
+	var entry map[string]any
+
+	if err := json.NewDecoder(output).Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry["user_id"] != "1" {
+		t.Errorf("expected the user to be logged, got %v", entry)
+	}
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"github.com/volix-dev/leopard"
+	"strconv"
+	"strings"
+)
+
+// sessionKey is the key of the ID of the user in the session.
+const sessionKey = "_auth_user"
+
+type sessionGuard struct {
+	provider UserProvider
+}
+
+// SessionGuard authenticates the users that logged in with Login, it needs the Sessions middleware.
+// Users that asked to be remembered are logged in again with their remember-me cookie when their session ended.
+func SessionGuard(provider UserProvider) Guard {
+	return &sessionGuard{provider: provider}
+}
+
+func (g *sessionGuard) Authenticate(c leopard.ContextInterface) (leopard.User, error) {
+	if id := c.Session().GetString(sessionKey); id != "" {
+		user, err := g.provider.FindByID(c.Request().Context(), id)
+
+		if err != nil || user != nil {
+			return user, err
+		}
+	}
+
+	return loginWithRememberToken(c, g.provider)
+}
+
+type bearerGuard struct {
+	lookup TokenLookup
+}
+
+// BearerGuard authenticates requests with a token in the Authorization header, like "Authorization: Bearer <token>".
+func BearerGuard(lookup TokenLookup) Guard {
+	return &bearerGuard{lookup: lookup}
+}
+
+func (g *bearerGuard) Authenticate(c leopard.ContextInterface) (leopard.User, error) {
+	token := BearerToken(c)
+
+	if token == "" {
+		return nil, nil
+	}
+
+	return g.lookup(c.Request().Context(), token)
+}
+
+func (g *bearerGuard) Challenge(c leopard.ContextInterface) {
+	c.Response().Header().Add("WWW-Authenticate", "Bearer")
+}
+
+// BearerToken gets the token of the Authorization header, or an empty string when there is none.
+func BearerToken(c leopard.ContextInterface) string {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+type basicGuard struct {
+	provider UserProvider
+	realm    string
+}
+
+// BasicGuard authenticates requests with basic auth, the realm is shown by browsers when they ask for the credentials.
+func BasicGuard(provider UserProvider, realm string) Guard {
+	return &basicGuard{provider: provider, realm: realm}
+}
+
+func (g *basicGuard) Authenticate(c leopard.ContextInterface) (leopard.User, error) {
+	username, password, ok := c.Request().BasicAuth()
+
+	if !ok {
+		return nil, nil
+	}
+
+	return g.provider.FindByCredentials(c.Request().Context(), username, password)
+}
+
+func (g *basicGuard) Challenge(c leopard.ContextInterface) {
+	c.Response().Header().Add("WWW-Authenticate", "Basic realm="+strconv.Quote(g.realm)+`, charset="UTF-8"`)
+}
+
+type apiKeyGuard struct {
+	header string
+	lookup TokenLookup
+}
+
+// APIKeyGuard authenticates requests with a key in the header, it defaults to X-API-Key.
+func APIKeyGuard(header string, lookup TokenLookup) Guard {
+	if header == "" {
+		header = "X-API-Key"
+	}
+
+	return &apiKeyGuard{header: header, lookup: lookup}
+}
+
+func (g *apiKeyGuard) Authenticate(c leopard.ContextInterface) (leopard.User, error) {
+	key := c.GetHeader(g.header)
+
+	if key == "" {
+		return nil, nil
+	}
+
+	return g.lookup(c.Request().Context(), key)
+}
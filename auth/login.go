@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/volix-dev/leopard"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RememberCookie is the name of the remember-me cookie.
+const RememberCookie = "leopard_remember"
+
+// RememberDuration is how long users that asked to be remembered stay logged in.
+var RememberDuration = 30 * 24 * time.Hour
+
+// rememberToken is a remember-me token as it is kept in the cache.
+// The cookie holds a selector to find the token and a validator, only the hash of the validator is kept.
+type rememberToken struct {
+	UserID string `json:"user_id"`
+	Hash   string `json:"hash"`
+}
+
+// Login logs the user in for the session of the request, it needs the Sessions middleware.
+// The session gets a new ID, so an ID that was planted in the browser of the user (session fixation) is worthless.
+// When remember is true the user gets a remember-me cookie, the SessionGuard logs them in again after their session ended.
+func Login(c leopard.ContextInterface, user leopard.User, remember bool) error {
+	session := c.Session()
+
+	if err := session.Regenerate(); err != nil {
+		return err
+	}
+
+	if err := session.Set(sessionKey, user.AuthID()); err != nil {
+		return err
+	}
+
+	c.SetUser(user)
+
+	if err := forgetRememberToken(c); err != nil {
+		return err
+	}
+
+	if !remember {
+		return nil
+	}
+
+	selector, validator := randomString(16), randomString(32)
+	data, err := json.Marshal(rememberToken{UserID: user.AuthID(), Hash: hashValidator(validator)})
+
+	if err != nil {
+		return err
+	}
+
+	seconds := int(RememberDuration.Seconds())
+
+	if err := c.App().Cache.SetTTL(rememberKey(selector), data, seconds); err != nil {
+		return err
+	}
+
+	c.SetResponseCookie(&http.Cookie{
+		Name:     RememberCookie,
+		Value:    selector + ":" + validator,
+		Path:     "/",
+		MaxAge:   seconds,
+		Secure:   c.Request().TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return nil
+}
+
+// Attempt logs in the user with the credentials, it returns nil when they do not match.
+func Attempt(c leopard.ContextInterface, provider UserProvider, username string, password string, remember bool) (leopard.User, error) {
+	user, err := provider.FindByCredentials(c.Request().Context(), username, password)
+
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	return user, Login(c, user, remember)
+}
+
+// Logout logs the user out, the session is destroyed and the remember-me token is removed.
+func Logout(c leopard.ContextInterface) error {
+	c.SetUser(nil)
+
+	if err := forgetRememberToken(c); err != nil {
+		return err
+	}
+
+	return c.Session().Destroy()
+}
+
+// loginWithRememberToken logs in the user of the remember-me cookie, the token is replaced by a new one.
+func loginWithRememberToken(c leopard.ContextInterface, provider UserProvider) (leopard.User, error) {
+	cookie, err := c.GetCookie(RememberCookie)
+
+	if err != nil {
+		return nil, nil
+	}
+
+	selector, validator, ok := strings.Cut(cookie.Value, ":")
+
+	if !ok {
+		return nil, nil
+	}
+
+	var data []byte
+	found, err := c.App().Cache.Get(rememberKey(selector), &data)
+
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var token rememberToken
+
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, nil
+	}
+
+	// A wrong validator means the token was stolen and already replaced, neither copy works anymore.
+	if subtle.ConstantTimeCompare([]byte(hashValidator(validator)), []byte(token.Hash)) != 1 {
+		return nil, c.App().Cache.Delete(rememberKey(selector))
+	}
+
+	user, err := provider.FindByID(c.Request().Context(), token.UserID)
+
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	return user, Login(c, user, true)
+}
+
+// forgetRememberToken removes the remember-me token of the request and its cookie.
+func forgetRememberToken(c leopard.ContextInterface) error {
+	cookie, err := c.GetCookie(RememberCookie)
+
+	if err != nil {
+		return nil
+	}
+
+	c.SetResponseCookie(&http.Cookie{Name: RememberCookie, Path: "/", MaxAge: -1})
+	selector, _, _ := strings.Cut(cookie.Value, ":")
+
+	return c.App().Cache.Delete(rememberKey(selector))
+}
+
+func rememberKey(selector string) string {
+	return "auth:remember:" + selector
+}
+
+func hashValidator(validator string) string {
+	hash := sha256.Sum256([]byte(validator))
+
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func randomString(size int) string {
+	data := make([]byte, size)
+
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,596 @@
+package leopard
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BindConfig configures how request bodies are read.
+// Zero values are read from the environment.
+type BindConfig struct {
+	// MaxBodySize is the maximum size of a request body in bytes, defaults to MAX_BODY_SIZE.
+	MaxBodySize int64
+
+	// MaxMemory is the number of bytes of a multipart form that are kept in memory, defaults to MULTIPART_MAX_MEMORY.
+	// The rest of the files is stored in temporary files, they are removed when the request is done.
+	MaxMemory int64
+
+	// DisallowUnknownFields makes Bind reject json bodies with fields that are not in the target struct,
+	// defaults to BIND_DISALLOW_UNKNOWN_FIELDS.
+	DisallowUnknownFields bool
+}
+
+// fillFromEnv sets all the zero values to the values from the environment.
+func (c *BindConfig) fillFromEnv() error {
+	sizes := []struct {
+		target       *int64
+		setting      string
+		defaultValue int
+	}{
+		{&c.MaxBodySize, "MAX_BODY_SIZE", 10 << 20},
+		{&c.MaxMemory, "MULTIPART_MAX_MEMORY", 1 << 20},
+	}
+
+	for _, s := range sizes {
+		if *s.target != 0 {
+			continue
+		}
+
+		size, err := envInt(s.setting, s.defaultValue)
+
+		if err != nil {
+			return err
+		}
+
+		*s.target = int64(size)
+	}
+
+	var err error
+
+	if !c.DisallowUnknownFields {
+		c.DisallowUnknownFields, err = envBool("BIND_DISALLOW_UNKNOWN_FIELDS", false)
+	}
+
+	return err
+}
+
+// bindConfig gets the bind config of the app, or the defaults when the app has no options.
+func (a *LeopardApp) bindConfig() BindConfig {
+	if a == nil || a.Options == nil || a.Binding.MaxBodySize == 0 {
+		return BindConfig{MaxBodySize: 10 << 20, MaxMemory: 1 << 20}
+	}
+
+	return a.Binding
+}
+
+// ErrorDetailer is implemented by errors that have details for the client, like the fields that failed.
+// The default error handler adds the details to the response as "errors".
+type ErrorDetailer interface {
+	ErrorDetails() interface{}
+}
+
+// FieldError describes a field that could not be bound.
+type FieldError struct {
+	// Field is the name of the field in the request, for example the query parameter.
+	Field string `json:"field"`
+
+	// Source is where the field comes from: body, param, query or header.
+	Source string `json:"source"`
+
+	// Message describes what is wrong with the value.
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s %q %s", e.Source, e.Field, e.Message)
+}
+
+// BindingError is returned by Bind when one or more fields could not be bound.
+// It responds with a 400 Bad Request that lists the fields.
+type BindingError struct {
+	Fields []FieldError
+}
+
+func (e *BindingError) Error() string {
+	messages := make([]string, len(e.Fields))
+
+	for i, field := range e.Fields {
+		messages[i] = field.Error()
+	}
+
+	return "invalid request: " + strings.Join(messages, ", ")
+}
+
+// StatusCode makes the error handler respond with a 400.
+func (e *BindingError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Unwrap makes the error match ErrBadRequest.
+func (e *BindingError) Unwrap() error {
+	return ErrBadRequest
+}
+
+// ErrorDetails returns the fields that failed.
+func (e *BindingError) ErrorDetails() interface{} {
+	return e.Fields
+}
+
+// Bind decodes the request into the struct dst points to.
+//
+// The body is decoded based on the Content-Type: json and xml use the json and xml tags,
+// url encoded and multipart forms use the form tag. After the body, fields tagged with
+// param, query and header are filled from the route parameters, the query and the headers.
+//
+//	type UpdateUser struct {
+//		ID    int    `param:"id"`
+//		Name  string `json:"name" form:"name"`
+//		Token string `header:"X-Token"`
+//	}
+//
+// A BindingError listing every failing field is returned when values have the wrong type.
+func (c *Context) Bind(dst interface{}) error {
+	target := reflect.ValueOf(dst)
+
+	if target.Kind() != reflect.Pointer || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind: expected a pointer to a struct, got %T", dst)
+	}
+
+	var fields []FieldError
+
+	if err := c.bindBody(dst, &fields); err != nil {
+		return err
+	}
+
+	sources := []struct {
+		tag    string
+		lookup func(key string) ([]string, bool)
+	}{
+		{"param", func(key string) ([]string, bool) {
+			value, ok := c.vars[key]
+
+			return []string{value}, ok
+		}},
+		{"query", func(key string) ([]string, bool) {
+			values, ok := c.request.URL.Query()[key]
+
+			return values, ok
+		}},
+		{"header", func(key string) ([]string, bool) {
+			values, ok := c.request.Header[http.CanonicalHeaderKey(key)]
+
+			return values, ok
+		}},
+	}
+
+	for _, source := range sources {
+		fields = append(fields, bindValues(target.Elem(), source.tag, source.lookup)...)
+	}
+
+	if len(fields) > 0 {
+		return &BindingError{Fields: fields}
+	}
+
+	return nil
+}
+
+// bindBody decodes the request body into dst, errors for single fields are added to fields.
+func (c *Context) bindBody(dst interface{}, fields *[]FieldError) error {
+	if c.request.Body == nil || c.request.Body == http.NoBody || c.request.ContentLength == 0 {
+		return nil
+	}
+
+	config := c.a.bindConfig()
+	c.limitBody(config.MaxBodySize)
+
+	mediaType, _, _ := mime.ParseMediaType(c.request.Header.Get("Content-Type"))
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		data, err := io.ReadAll(c.request.Body)
+
+		if err != nil {
+			return jsonBindError(err, fields)
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+
+		if config.DisallowUnknownFields {
+			decoder.DisallowUnknownFields()
+		}
+
+		err = decoder.Decode(dst)
+
+		// encoding/json only reports the first failing field, the fields are checked one by one to find all of them.
+		if found := jsonFieldErrors(data, reflect.TypeOf(dst), "", config.DisallowUnknownFields); err != nil && len(found) > 0 {
+			*fields = append(*fields, found...)
+
+			return nil
+		}
+
+		return jsonBindError(err, fields)
+
+	case mediaType == "application/xml" || mediaType == "text/xml":
+		err := xml.NewDecoder(c.request.Body).Decode(dst)
+
+		if err == nil || errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if isBodyTooLarge(err) {
+			return NewHTTPError(http.StatusRequestEntityTooLarge, "").WithInternal(err)
+		}
+
+		return NewHTTPError(http.StatusBadRequest, "malformed xml body").WithInternal(err)
+
+	case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+		if err := c.parseForm(config); err != nil {
+			return err
+		}
+
+		*fields = append(*fields, bindValues(reflect.ValueOf(dst).Elem(), "form", func(key string) ([]string, bool) {
+			values, ok := c.request.PostForm[key]
+
+			return values, ok
+		})...)
+
+		return nil
+	}
+
+	return NewHTTPError(http.StatusUnsupportedMediaType, "").
+		WithInternal(fmt.Errorf("can not bind content type %q", mediaType))
+}
+
+// limitBody limits the size of the request body, the first limit wins.
+func (c *Context) limitBody(size int64) {
+	if c.bodyLimited || size <= 0 {
+		return
+	}
+
+	c.request.Body = http.MaxBytesReader(c.response, c.request.Body, size)
+	c.bodyLimited = true
+}
+
+// parseForm parses url encoded and multipart forms.
+func (c *Context) parseForm(config BindConfig) error {
+	c.limitBody(config.MaxBodySize)
+
+	// ParseMultipartForm ignores the errors of url encoded bodies, so they are parsed first.
+	err := c.request.ParseForm()
+
+	if err == nil {
+		err = c.request.ParseMultipartForm(config.MaxMemory)
+	}
+
+	if errors.Is(err, http.ErrNotMultipart) {
+		err = nil
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	if isBodyTooLarge(err) {
+		return NewHTTPError(http.StatusRequestEntityTooLarge, "").WithInternal(err)
+	}
+
+	return NewHTTPError(http.StatusBadRequest, "malformed form body").WithInternal(err)
+}
+
+// jsonBindError converts json decoding errors to field errors where possible.
+func jsonBindError(err error, fields *[]FieldError) error {
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case err == nil || errors.Is(err, io.EOF):
+		return nil
+
+	case isBodyTooLarge(err):
+		return NewHTTPError(http.StatusRequestEntityTooLarge, "").WithInternal(err)
+
+	case errors.As(err, &typeErr):
+		*fields = append(*fields, FieldError{
+			Field:   typeErr.Field,
+			Source:  "body",
+			Message: "must be " + describeKind(typeErr.Type),
+		})
+
+		return nil
+
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+
+		*fields = append(*fields, FieldError{Field: field, Source: "body", Message: "is not allowed"})
+
+		return nil
+	}
+
+	return NewHTTPError(http.StatusBadRequest, "malformed json body").WithInternal(err)
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// jsonFieldErrors decodes the json value field by field into the type and returns the fields that failed.
+// Unknown fields of objects are reported as well when they are not allowed.
+func jsonFieldErrors(data []byte, t reflect.Type, path string, disallowUnknown bool) []FieldError {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	custom := reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType)
+
+	switch {
+	case custom || bytes.Equal(bytes.TrimSpace(data), []byte("null")):
+
+	case t.Kind() == reflect.Struct:
+		var object map[string]json.RawMessage
+
+		if err := json.Unmarshal(data, &object); err != nil {
+			return jsonTypeError(err, t, path)
+		}
+
+		var fields []FieldError
+		known := map[string]bool{}
+
+		for _, field := range jsonFields(t) {
+			for key, raw := range object {
+				if key == field.name || (!known[key] && strings.EqualFold(key, field.name)) {
+					known[key] = true
+					fields = append(fields, jsonFieldErrors(raw, field.Type, joinFieldPath(path, field.name), disallowUnknown)...)
+
+					break
+				}
+			}
+		}
+
+		if disallowUnknown {
+			var unknown []string
+
+			for key := range object {
+				if !known[key] {
+					unknown = append(unknown, key)
+				}
+			}
+
+			sort.Strings(unknown)
+
+			for _, key := range unknown {
+				fields = append(fields, FieldError{Field: joinFieldPath(path, key), Source: "body", Message: "is not allowed"})
+			}
+		}
+
+		return fields
+
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		var items []json.RawMessage
+
+		if err := json.Unmarshal(data, &items); err == nil && t.Elem().Kind() != reflect.Uint8 {
+			var fields []FieldError
+
+			for i, item := range items {
+				fields = append(fields, jsonFieldErrors(item, t.Elem(), joinFieldPath(path, strconv.Itoa(i)), disallowUnknown)...)
+			}
+
+			return fields
+		}
+	}
+
+	return jsonTypeError(json.Unmarshal(data, reflect.New(t).Interface()), t, path)
+}
+
+// jsonTypeError converts an error for a value of the wrong type to a field error, other errors are ignored.
+func jsonTypeError(err error, t reflect.Type, path string) []FieldError {
+	var typeErr *json.UnmarshalTypeError
+
+	if !errors.As(err, &typeErr) {
+		return nil
+	}
+
+	return []FieldError{{Field: path, Source: "body", Message: "must be " + describeKind(t)}}
+}
+
+// jsonField is a field of a struct with the name it has in json.
+type jsonField struct {
+	reflect.StructField
+	name string
+}
+
+// jsonFields gets the fields of the struct that encoding/json decodes, the fields of embedded structs are included.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(embedded)...)
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, jsonField{field, name})
+	}
+
+	return fields
+}
+
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// isBodyTooLarge checks if the error comes from a body that exceeded the limit of http.MaxBytesReader.
+func isBodyTooLarge(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "http: request body too large")
+}
+
+// bindValues sets the fields of the struct that have the tag to the values found by lookup.
+// Embedded structs are bound as well.
+func bindValues(v reflect.Value, tag string, lookup func(key string) ([]string, bool)) []FieldError {
+	var fields []FieldError
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, ok := field.Tag.Lookup(tag)
+
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				fields = append(fields, bindValues(v.Field(i), tag, lookup)...)
+			}
+
+			continue
+		}
+
+		name, _, _ = strings.Cut(name, ",")
+
+		if name == "" || name == "-" {
+			continue
+		}
+
+		values, ok := lookup(name)
+
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		if err := setValue(v.Field(i), values); err != nil {
+			fields = append(fields, FieldError{Field: name, Source: tag, Message: err.Error()})
+		}
+	}
+
+	return fields
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// setValue converts the values to the type of v.
+// Only slices use more than the first value.
+func setValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return setValue(v.Elem(), values)
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(values[0])); err != nil {
+			return errors.New("is invalid")
+		}
+
+		return nil
+	}
+
+	var err error
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(values[0])
+
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(values[0])
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(values[0], 10, v.Type().Bits())
+		v.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(values[0], 10, v.Type().Bits())
+		v.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(values[0], v.Type().Bits())
+		v.SetFloat(f)
+
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+
+		for i, value := range values {
+			if err := setValue(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+
+		v.Set(slice)
+
+		return nil
+
+	default:
+		return fmt.Errorf("has an unsupported type %s", v.Type())
+	}
+
+	if err != nil {
+		return errors.New("must be " + describeKind(v.Type()))
+	}
+
+	return nil
+}
+
+// describeKind describes the type for error messages.
+func describeKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a positive integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+
+	return "a " + t.String()
+}
@@ -0,0 +1,145 @@
+package leopard_test
+
+import (
+	"bytes"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type updateUser struct {
+	ID    int      `param:"id"`
+	Name  string   `json:"name" xml:"name" form:"name"`
+	Age   int      `json:"age" xml:"age" form:"age"`
+	Tags  []string `query:"tag"`
+	Page  *int     `query:"page"`
+	Token string   `header:"X-Token"`
+}
+
+func bindApp(t *testing.T, opts ...leopard.Option) *leopardtest.App {
+	a := leopardtest.New(t, opts...)
+
+	a.POST("/users/{id}", func(c leopard.ContextInterface) error {
+		var user updateUser
+
+		if err := c.Bind(&user); err != nil {
+			return err
+		}
+
+		return c.Json(user)
+	})
+
+	return a
+}
+
+func TestBindJSON(t *testing.T) {
+	client := bindApp(t).Test()
+
+	client.POST("/users/7?tag=a&tag=b&page=2").
+		WithHeader("X-Token", "secret").
+		WithJSON(map[string]any{"name": "Ada", "age": 36}).
+		Expect(t).
+		Status(200).
+		JSONPath("ID", 7).
+		JSONPath("name", "Ada").
+		JSONPath("age", 36).
+		JSONPath("Tags", []string{"a", "b"}).
+		JSONPath("Page", 2).
+		JSONPath("Token", "secret")
+}
+
+func TestBindForms(t *testing.T) {
+	client := bindApp(t).Test()
+
+	client.POST("/users/7").
+		WithForm(url.Values{"name": {"Ada"}, "age": {"36"}}).
+		Expect(t).
+		Status(200).
+		JSONPath("name", "Ada").
+		JSONPath("age", 36)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	_ = writer.WriteField("name", "Grace")
+	_ = writer.Close()
+
+	client.POST("/users/7").
+		WithHeader("Content-Type", writer.FormDataContentType()).
+		WithBody(&body).
+		Expect(t).
+		Status(200).
+		JSONPath("name", "Grace")
+
+	client.POST("/users/7").
+		WithHeader("Content-Type", "application/xml").
+		WithBody(strings.NewReader("<user><name>Linus</name><age>53</age></user>")).
+		Expect(t).
+		Status(200).
+		JSONPath("name", "Linus").
+		JSONPath("age", 53)
+}
+
+func TestBindErrors(t *testing.T) {
+	client := bindApp(t).Test()
+
+	client.POST("/users/abc?page=two").
+		WithJSON(map[string]any{"name": 36, "age": "old"}).
+		Expect(t).
+		Status(400).
+		JSONPath("errors", []leopard.FieldError{
+			{Field: "name", Source: "body", Message: "must be a string"},
+			{Field: "age", Source: "body", Message: "must be an integer"},
+			{Field: "id", Source: "param", Message: "must be an integer"},
+			{Field: "page", Source: "query", Message: "must be an integer"},
+		})
+
+	client.POST("/users/7").
+		WithHeader("Content-Type", "application/json").
+		WithBody(strings.NewReader(`{"name":`)).
+		Expect(t).
+		Status(400).
+		JSONPath("message", "malformed json body")
+
+	client.POST("/users/7").
+		WithHeader("Content-Type", "text/csv").
+		WithBody(strings.NewReader("name\nAda")).
+		Expect(t).
+		Status(415)
+}
+
+func TestBindLimits(t *testing.T) {
+	client := bindApp(t, leopard.WithBindConfig(leopard.BindConfig{
+		MaxBodySize:           16,
+		DisallowUnknownFields: true,
+	})).Test()
+
+	client.POST("/users/7").
+		WithJSON(map[string]any{"name": strings.Repeat("a", 32)}).
+		Expect(t).
+		Status(413)
+
+	client.POST("/users/7").
+		WithJSON(map[string]any{"admin": true}).
+		Expect(t).
+		Status(400).
+		JSONPath("errors.0", leopard.FieldError{Field: "admin", Source: "body", Message: "is not allowed"})
+}
+
+func TestReadForm(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.POST("/form", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString(strings.Join(c.ReadForm()["name"], ","))
+
+		return err
+	})
+
+	a.Test().POST("/form?name=query").
+		WithForm(url.Values{"name": {"body"}}).
+		Expect(t).
+		Status(200).
+		BodyEquals("body,query")
+}
@@ -1,8 +1,10 @@
 package leopard
 
 import (
-	"fmt"
+	"errors"
 	"github.com/volix-dev/leopard/caching"
+	cacheDrivers "github.com/volix-dev/leopard/caching/drivers"
+	"strconv"
 )
 
 type Caching struct {
@@ -21,6 +23,41 @@ func newCaching(driver string, config any) (*Caching, error) {
 	}, nil
 }
 
+// cacheFromEnv creates the caching driver configured by CACHE_DRIVER.
+func (a *LeopardApp) cacheFromEnv() (*Caching, error) {
+	driverName := EnvSettingD("CACHE_DRIVER", "memory").GetValue().(string)
+
+	switch driverName {
+	case "redis":
+		port, err := strconv.Atoi(EnvSettingD("REDIS_PORT", "6379").GetValue().(string))
+
+		if err != nil {
+			return nil, err
+		}
+
+		db, err := strconv.Atoi(EnvSettingD("REDIS_DB", "0").GetValue().(string))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return newCaching(driverName, cacheDrivers.RedisSettings{
+			Host:     EnvSettingD("REDIS_HOST", "localhost").GetValue().(string),
+			Port:     port,
+			Password: EnvSettingD("REDIS_PASSWORD", "").GetValue().(string),
+			Database: db,
+		})
+
+	case "memory":
+		a.logger().Warning(`Using the memory cache driver, this is not intended to be used in production.
+You can change it by setting CACHE_DRIVER to "redis" in the .env file or in the environment variables.`)
+
+		return newCaching(driverName, nil)
+	}
+
+	return nil, errors.New("cache driver not found")
+}
+
 // Caching functions
 
 // Get retrieves data from the cache.
@@ -32,8 +69,7 @@ func (c Caching) Get(key string, target any) (bool, error) {
 	}
 
 	if get {
-		fmt.Println(target)
-
+		Debug("cache hit: ", key)
 	}
 
 	return get, nil
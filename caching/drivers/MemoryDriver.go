@@ -1,8 +1,10 @@
 package drivers
 
 import (
-	"fmt"
+	"encoding/json"
+	"errors"
 	"github.com/volix-dev/leopard/caching"
+	"reflect"
 	"sync"
 	"time"
 )
@@ -11,67 +13,114 @@ type MemoryDriver struct {
 	cache   map[string]any
 	lock    sync.RWMutex
 	expires map[string]time.Time
+	ticker  *time.Ticker
+	stop    chan struct{}
+	closed  sync.Once
 }
 
 func init() {
 	caching.Register("memory", func(config any) (caching.Driver, error) {
-		timer := time.NewTimer(time.Minute)
-
 		driver := &MemoryDriver{
 			cache:   make(map[string]any),
 			lock:    sync.RWMutex{},
 			expires: make(map[string]time.Time),
+			ticker:  time.NewTicker(time.Minute),
+			stop:    make(chan struct{}),
 		}
 
-		go func() {
-			for {
-				<-timer.C
-
-				for key, expireTime := range driver.expires {
-					if expireTime.Before(time.Now()) {
-						driver.lock.Lock()
-						delete(driver.cache, key)
-						delete(driver.expires, key)
-						driver.lock.Unlock()
-					}
-				}
-			}
-		}()
+		go driver.removeExpired()
 
 		return driver, nil
 	})
 }
 
+// removeExpired frees the memory of expired values every tick until the driver is closed, Get already ignores them.
+func (m *MemoryDriver) removeExpired() {
+	for {
+		select {
+		case <-m.stop:
+			return
+
+		case now := <-m.ticker.C:
+			m.lock.Lock()
+
+			for key, expireTime := range m.expires {
+				if expireTime.Before(now) {
+					delete(m.cache, key)
+					delete(m.expires, key)
+				}
+			}
+
+			m.lock.Unlock()
+		}
+	}
+}
+
 func (m *MemoryDriver) Get(key string, target any) (bool, error) {
 	m.lock.RLock()
 	defer m.lock.RUnlock()
 
-	if value, ok := m.cache[key]; ok {
-		switch t := target.(type) {
-		case *string:
-			*t = value.(string)
-		}
+	if expireTime, ok := m.expires[key]; ok && !expireTime.After(time.Now()) {
+		return false, nil
+	}
 
-		return true, nil
+	if value, ok := m.cache[key]; ok {
+		return true, assign(value, target)
 	}
 
 	return false, nil
 }
 
+// assign stores the cached value in the target pointer.
+// Values that can not be assigned directly are converted by marshalling them to json.
+func assign(value any, target any) error {
+	targetValue := reflect.ValueOf(target)
+
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() {
+		return errors.New("target must be a non nil pointer")
+	}
+
+	elem := targetValue.Elem()
+	cached := reflect.ValueOf(value)
+
+	if cached.IsValid() && cached.Type().AssignableTo(elem.Type()) {
+		elem.Set(cached)
+
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
 func (m *MemoryDriver) Set(key string, value any) error {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
 	m.cache[key] = value
+	delete(m.expires, key)
 
 	return nil
 }
 
+// SetTTL sets the value for the key, it expires after ttl seconds. A ttl of 0 keeps the value forever.
 func (m *MemoryDriver) SetTTL(key string, value any, ttl int) error {
-	err := m.Set(key, value)
-	m.expires[key] = time.Now().Add(time.Duration(ttl) * time.Second)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.cache[key] = value
+	delete(m.expires, key)
+
+	if ttl > 0 {
+		m.expires[key] = time.Now().Add(time.Duration(ttl) * time.Second)
+	}
 
-	return err
+	return nil
 }
 
 func (m *MemoryDriver) Delete(key string) error {
@@ -79,18 +128,21 @@ func (m *MemoryDriver) Delete(key string) error {
 	defer m.lock.Unlock()
 
 	delete(m.cache, key)
+	delete(m.expires, key)
 
 	return nil
 }
 
+// Close stops removing the expired values, the values stay in memory.
 func (m *MemoryDriver) Close() error {
+	m.closed.Do(func() {
+		m.ticker.Stop()
+		close(m.stop)
+	})
+
 	return nil
 }
 
 func (m *MemoryDriver) Open() error {
-	fmt.Println(`Started memory driver
-This is not intended to be used in production.
-You can change it by setting CACHE_DRIVER to "redis" in the .env file or in the environment variables.`)
-
 	return nil
 }
@@ -64,7 +64,13 @@ func (r *RedisDriver) Get(key string, target any) (bool, error) {
 			}
 		}
 	}
-	return true, r.client.Get(context.TODO(), key).Scan(target)
+	err := r.client.Get(context.TODO(), key).Scan(target)
+
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+
+	return err == nil, err
 }
 
 func (r *RedisDriver) Set(key string, value any) error {
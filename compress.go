@@ -0,0 +1,325 @@
+package leopard
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CompressConfig configures the Compress middleware.
+type CompressConfig struct {
+	// Encodings are the supported encodings in order of preference, defaults to br, zstd and gzip.
+	// The preference of the client, from the Accept-Encoding header, comes first.
+	Encodings []string
+
+	// MinSize is the minimum size of a body in bytes to compress it, defaults to 1024.
+	// Compressing smaller bodies costs more than it saves.
+	MinSize int
+
+	// ExcludedTypes are the content types that are already compressed, defaults to DefaultExcludedTypes.
+	// Types ending with a slash, like video/, exclude all their subtypes.
+	ExcludedTypes []string
+
+	// Skip decides if a response is not compressed.
+	Skip func(c ContextInterface) bool
+}
+
+// DefaultExcludedTypes are compressed formats that do not get smaller when they are compressed again.
+var DefaultExcludedTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/", "audio/", "font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-7z-compressed", "application/x-rar-compressed", "application/octet-stream",
+}
+
+// encoder is implemented by the writers of all supported encodings, so they can be reused.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// encoderPools keeps the encoders per encoding, creating them allocates large buffers.
+var encoderPools = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+	"br": {New: func() any {
+		return brotli.NewWriterLevel(io.Discard, 4)
+	}},
+	"zstd": {New: func() any {
+		writer, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+
+		return writer
+	}},
+}
+
+// Compress compresses response bodies with the encoding the client prefers.
+// Small bodies, compressed content types, partial content and bodies that already have a
+// Content-Encoding, like the precompressed variants of the static server, are sent as is.
+//
+// Errors returned by the rest of the chain are passed to the error handler here,
+// so error responses are compressed as well.
+func Compress(config CompressConfig) HandlerFunc {
+	if len(config.Encodings) == 0 {
+		config.Encodings = []string{"br", "zstd", "gzip"}
+	}
+
+	if config.MinSize == 0 {
+		config.MinSize = 1024
+	}
+
+	if config.ExcludedTypes == nil {
+		config.ExcludedTypes = DefaultExcludedTypes
+	}
+
+	for _, encoding := range config.Encodings {
+		if _, ok := encoderPools[encoding]; !ok {
+			panic("compress: unsupported encoding " + encoding)
+		}
+	}
+
+	return func(c ContextInterface) (err error) {
+		hc, ok := c.(httpContext)
+
+		if !ok || (config.Skip != nil && config.Skip(c)) {
+			return c.Next()
+		}
+
+		c.Response().Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), config.Encodings)
+
+		if encoding == "" || c.Request().Method == http.MethodHead {
+			return c.Next()
+		}
+
+		original := c.Response()
+		writer := &compressWriter{
+			Response: original,
+			config:   &config,
+			encoding: encoding,
+		}
+
+		hc.setResponseWriter(NewResponse(writer))
+
+		// Panics are handled here as well, otherwise the error response would stay in the buffer.
+		defer func() {
+			if r := recover(); r != nil {
+				if r == http.ErrAbortHandler {
+					hc.setResponseWriter(original)
+					panic(r)
+				}
+
+				_ = c.Error(panicError(r))
+			}
+
+			closeErr := writer.Close()
+			hc.setResponseWriter(original)
+
+			if err == nil {
+				err = closeErr
+			}
+		}()
+
+		if err := c.Next(); err != nil {
+			_ = c.Error(err)
+		}
+
+		return nil
+	}
+}
+
+// negotiateEncoding picks the encoding with the highest quality in the Accept-Encoding header.
+// The order of the offers decides between encodings with the same quality.
+func negotiateEncoding(header string, offers []string) string {
+	best, bestQuality := "", 0.0
+
+	for _, offer := range offers {
+		quality := -1.0
+
+		for _, r := range parseAccept(header) {
+			if r.mediaType == offer || (r.mediaType == "*" && quality < 0) {
+				quality = r.quality
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+
+	return best
+}
+
+// compressWriter buffers the start of the body until it knows if the body should be compressed.
+type compressWriter struct {
+	*Response
+
+	config   *CompressConfig
+	encoding string
+	status   int
+	buffer   []byte
+	decided  bool
+	encoder  encoder
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if !w.decided {
+		w.buffer = append(w.buffer, data...)
+
+		if len(w.buffer) < w.config.MinSize {
+			return len(data), nil
+		}
+
+		return len(data), w.decide(true)
+	}
+
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+
+	return w.Response.Write(data)
+}
+
+// decide writes the headers and the buffered body, compressed if the response qualifies.
+func (w *compressWriter) decide(bigEnough bool) error {
+	w.decided = true
+	header := w.Header()
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	if bigEnough && w.compressible() {
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		header.Set("Content-Encoding", w.encoding)
+
+		// The compressed body is not byte for byte the same as the original.
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		w.encoder = encoderPools[w.encoding].Get().(encoder)
+		w.encoder.Reset(w.Response)
+	}
+
+	w.Response.WriteHeader(w.status)
+
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	buffer := w.buffer
+	w.buffer = nil
+
+	if w.encoder != nil {
+		_, err := w.encoder.Write(buffer)
+
+		return err
+	}
+
+	_, err := w.Response.Write(buffer)
+
+	return err
+}
+
+// compressible checks the status code and the headers of the response.
+func (w *compressWriter) compressible() bool {
+	header := w.Header()
+
+	if w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified ||
+		w.status == http.StatusPartialContent || header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buffer)
+		header.Set("Content-Type", contentType)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	for _, excluded := range w.config.ExcludedTypes {
+		if mediaType == excluded || (strings.HasSuffix(excluded, "/") && strings.HasPrefix(mediaType, excluded)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Flush compresses the buffered data and sends it to the client, streaming responses are compressed regardless of their size.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+
+	w.Response.Flush()
+}
+
+// Hijack lets the caller take over the connection, nothing is compressed then.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.decided {
+		return nil, nil, errors.New("compress: the response was already written")
+	}
+
+	w.decided = true
+
+	return w.Response.Hijack()
+}
+
+// Unwrap returns the wrapped response writer, it is used by http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.Response
+}
+
+// Close writes the rest of the body and returns the encoder to its pool.
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if w.status == 0 && len(w.buffer) == 0 {
+			return nil
+		}
+
+		if err := w.decide(len(w.buffer) >= w.config.MinSize); err != nil {
+			return err
+		}
+	}
+
+	if w.encoder == nil {
+		return nil
+	}
+
+	err := w.encoder.Close()
+	w.encoder.Reset(io.Discard)
+	encoderPools[w.encoding].Put(w.encoder)
+	w.encoder = nil
+
+	return err
+}
@@ -0,0 +1,170 @@
+package leopard_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"github.com/volix-dev/leopard/static"
+	"io"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var large = strings.Repeat("leopard ", 512)
+
+func compressApp(t *testing.T) *leopardtest.App {
+	a := leopardtest.New(t)
+
+	a.Use(leopard.Compress(leopard.CompressConfig{}))
+
+	a.GET("/large", func(c leopard.ContextInterface) error {
+		return c.Json(map[string]string{"text": large})
+	})
+
+	a.GET("/small", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString("small")
+
+		return err
+	})
+
+	a.GET("/png", func(c leopard.ContextInterface) error {
+		c.SetHeader("Content-Type", "image/png")
+		_, err := c.WriteString(large)
+
+		return err
+	})
+
+	a.GET("/error", func(c leopard.ContextInterface) error {
+		return leopard.NewHTTPError(400, large)
+	})
+
+	a.StaticFS("/static", fstest.MapFS{"app.js": {Data: []byte(large)}}, static.Config{})
+
+	return a
+}
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	var reader io.Reader
+	var err error
+
+	switch encoding {
+	case "gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(body))
+	case "zstd":
+		reader, err = zstd.NewReader(bytes.NewReader(body))
+	default:
+		return string(body)
+	}
+
+	if err == nil {
+		body, err = io.ReadAll(reader)
+	}
+
+	if err != nil {
+		t.Fatalf("failed to decompress %s: %v", encoding, err)
+	}
+
+	return string(body)
+}
+
+func TestCompress(t *testing.T) {
+	client := compressApp(t).Test()
+
+	encodings := map[string]string{
+		"gzip":               "gzip",
+		"gzip, deflate, br":  "br",
+		"zstd;q=1, br;q=0.5": "zstd",
+		"*":                  "br",
+		"identity":           "",
+		"br;q=0, gzip;q=0.1": "gzip",
+		"":                   "",
+	}
+
+	for acceptEncoding, expected := range encodings {
+		response := client.GET("/large").WithHeader("Accept-Encoding", acceptEncoding).Expect(t).
+			Status(200).
+			Header("Content-Encoding", expected).
+			Header("Vary", "Accept-Encoding")
+
+		if body := decompress(t, expected, response.Body()); !strings.Contains(body, large) {
+			t.Errorf("expected the decompressed body for %q, got %d bytes", acceptEncoding, len(body))
+		}
+	}
+
+	client.GET("/small").WithHeader("Accept-Encoding", "gzip").Expect(t).
+		Header("Content-Encoding", "").
+		BodyEquals("small")
+
+	client.GET("/png").WithHeader("Accept-Encoding", "gzip").Expect(t).
+		Header("Content-Encoding", "").
+		BodyEquals(large)
+
+	response := client.GET("/error").WithHeader("Accept-Encoding", "gzip").Expect(t).
+		Status(400).
+		Header("Content-Encoding", "gzip")
+
+	if !strings.Contains(decompress(t, "gzip", response.Body()), large) {
+		t.Error("expected the error response to be compressed")
+	}
+}
+
+func TestCompressStatic(t *testing.T) {
+	client := compressApp(t).Test()
+
+	response := client.GET("/static/app.js").WithHeader("Accept-Encoding", "gzip").Expect(t).
+		Status(200).
+		Header("Content-Encoding", "gzip").
+		Header("Content-Length", "").
+		Header("Accept-Ranges", "")
+
+	etag := response.Response.Header.Get("ETag")
+
+	if !strings.HasPrefix(etag, "W/") || decompress(t, "gzip", response.Body()) != large {
+		t.Errorf("expected a weak etag and the file, got %q", etag)
+	}
+
+	client.GET("/static/app.js").
+		WithHeader("Accept-Encoding", "gzip").
+		WithHeader("If-None-Match", etag).
+		Expect(t).
+		Status(304)
+
+	client.GET("/static/app.js").
+		WithHeader("Accept-Encoding", "gzip").
+		WithHeader("Range", "bytes=0-6").
+		Expect(t).
+		Status(206).
+		Header("Content-Encoding", "").
+		BodyEquals("leopard")
+}
+
+func TestCompressPanic(t *testing.T) {
+	for _, env := range []string{"DEVELOPMENT", "PRODUCTION"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv("LEOPARD_ENV", env)
+
+			a := leopardtest.New(t)
+
+			a.Use(leopard.Compress(leopard.CompressConfig{MinSize: 1}))
+
+			a.GET("/panic", func(c leopard.ContextInterface) {
+				panic("something broke")
+			})
+
+			response := a.Test().GET("/panic").WithHeader("Accept-Encoding", "gzip").Expect(t).Status(500)
+			body := decompress(t, response.Response.Header.Get("Content-Encoding"), response.Body())
+
+			if !strings.Contains(body, "message") {
+				t.Errorf("expected the error response, got %q", body)
+			}
+		})
+	}
+}
@@ -5,15 +5,18 @@ import (
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/volix-dev/leopard/helpers"
+	"github.com/volix-dev/leopard/logging"
+	"github.com/volix-dev/leopard/sessions"
 	"github.com/volix-dev/leopard/templating/drivers"
 	"io/ioutil"
 	"net/http"
-	"runtime/debug"
+	"time"
 )
 
 type ContextInterface interface {
 	Request() *http.Request
 	ResponseWriter() http.ResponseWriter
+	Response() *Response
 	App() *LeopardApp
 
 	JsonStatus(status int, data interface{}) error
@@ -34,23 +37,53 @@ type ContextInterface interface {
 	ReadString() (string, error)
 	ReadJson(data interface{}) error
 	ReadForm() map[string][]string
+	Bind(dst interface{}) error
+	Validate(dst interface{}) error
+	BindAndValidate(dst interface{}) error
+	File(name string) (*Upload, error)
+	Files(name string) ([]*Upload, error)
+	SendFile(path string) error
+	Download(path string, filename string) error
 	ReadFormValue(key string) string
 	SetHeader(key, value string)
 	SetHeaders(headers map[string][]string)
 	GetHeader(key string) string
 	GetHeaders() map[string][]string
+	Accepts(offers ...string) string
 	GetParam(key string) string
 	HasParam(key string) bool
 	GetParams() map[string]string
+	Param(key string) any
+	ParamInt(key string) (int, error)
+	ParamUUID(key string) (helpers.UUID, error)
+	ParamTime(key string, layout string) (time.Time, error)
 	GetQuery(key string) string
 	Queries() map[string][]string
+	QueryInt(key string, defaultValue int) (int, error)
+	QueryBool(key string, defaultValue bool) (bool, error)
+	QuerySlice(key string) []string
 	GetCookie(key string) (*http.Cookie, error)
 	SetCookie(key string, value string, maxAge int, path string, domain string, secure bool, httpOnly bool)
 	SetResponseCookie(cookies ...*http.Cookie)
+	Session() *sessions.Session
+	CSRFToken() string
+	Flash(key string, message string)
+	WithInput(input map[string][]string)
+	WithErrors(err error)
+	Flashes(key string) []string
+	Old(field string) string
+	FieldErrors(field string) []string
+	User() User
+	IsAuthenticated() bool
+	SetUser(user User)
 	RenderTemplate(template string, data map[string]drivers.Value) error
+	Logger() LoggerInterface
+	RequestID() string
+	AddLogFields(fields Fields)
 
 	// Used for middleware only
 
+	Next() error
 	Aborted() bool
 	Abort()
 }
@@ -58,16 +91,25 @@ type ContextInterface interface {
 type Context struct {
 	request        *http.Request
 	responseWriter http.ResponseWriter
+	response       *Response
 	vars           map[string]string
+	params         map[string]any
 	a              *LeopardApp
+	bodyLimited    bool
 
-	abort bool
+	abort    bool
+	handlers []HandlerFunc
+	index    int
+	logger   LoggerInterface
 }
 
 func NewContext(w http.ResponseWriter, r *http.Request, a *LeopardApp) *Context {
+	response := NewResponse(w)
+
 	return &Context{
 		request:        r,
-		responseWriter: w,
+		responseWriter: response,
+		response:       response,
 		vars:           mux.Vars(r),
 		a:              a,
 	}
@@ -83,6 +125,16 @@ func (c *Context) ResponseWriter() http.ResponseWriter {
 	return c.responseWriter
 }
 
+// Response returns the response recorder, it knows the status code and the number of bytes written.
+func (c *Context) Response() *Response {
+	if c.response == nil {
+		c.response = NewResponse(c.responseWriter)
+		c.responseWriter = c.response
+	}
+
+	return c.response
+}
+
 // App returns the current LeopardApp
 func (c *Context) App() *LeopardApp {
 	return c.a
@@ -103,12 +155,14 @@ func (c *Context) Json(data interface{}) error {
 	return c.JsonStatus(http.StatusOK, data)
 }
 
-// Error responds with a 500 and the error's message in a json.
+// Error responds with the error using the app's ErrorHandler.
+// An HTTPError responds with its status code, all other errors with a 500.
 func (c *Context) Error(err error) error {
-	return c.JsonStatus(http.StatusInternalServerError, map[string]interface{}{
-		"message":    err.Error(),
-		"stacktrace": helpers.SerializeStack(debug.Stack()),
-	})
+	if c.a == nil || c.a.ErrorHandler == nil {
+		return DefaultErrorHandler(c, err)
+	}
+
+	return c.a.ErrorHandler(c, err)
 }
 
 // Status responds with the provided status code.
@@ -187,14 +241,23 @@ func (c *Context) ReadJson(data interface{}) error {
 
 // Forms
 
-// ReadForm reads the request body as a form.
+// ReadForm reads the request body as a form, url encoded and multipart forms are supported.
+// The values of the query are included, values from the body come first.
 func (c *Context) ReadForm() map[string][]string {
+	if err := c.parseForm(c.a.bindConfig()); err != nil {
+		c.Logger().Debug("could not parse form: ", err)
+	}
+
 	return c.request.Form
 }
 
 // ReadFormValue reads the request body as a form and returns the value of the provided key.
 func (c *Context) ReadFormValue(key string) string {
-	return c.request.FormValue(key)
+	if values := c.ReadForm()[key]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
 }
 
 // Headers
@@ -221,6 +284,12 @@ func (c *Context) GetHeaders() map[string][]string {
 	return c.Request().Header
 }
 
+// Accepts returns the offered content type the client prefers according to the Accept header.
+// The first offer is returned when the client accepts anything, an empty string when it accepts none of them.
+func (c *Context) Accepts(offers ...string) string {
+	return negotiate(c.GetHeader("Accept"), offers...)
+}
+
 // Params
 
 // GetParam gets the provided key from the request params.
@@ -283,12 +352,104 @@ func (c *Context) SetResponseCookie(cookies ...*http.Cookie) {
 
 // Templates
 
+// RenderTemplate renders the template to the response.
+// The helpers of the request, like csrf_token, are passed with the data.
 func (c *Context) RenderTemplate(template string, data map[string]drivers.Value) error {
-	return c.a.TemplateDriver.RenderTemplate(template, c.responseWriter, data)
+	return c.a.TemplateDriver.RenderTemplate(template, c.responseWriter, c.templateData(data))
+}
+
+// templateData copies the data and adds the helpers of the request.
+func (c *Context) templateData(data map[string]drivers.Value) map[string]drivers.Value {
+	templateData := make(map[string]drivers.Value, len(data)+1)
+
+	for key, value := range data {
+		templateData[key] = value
+	}
+
+	templateHelpers := drivers.Helpers{
+		"csrf_token": func(args ...drivers.Value) drivers.Value {
+			return c.CSRFToken()
+		},
+		"csrf_field": func(args ...drivers.Value) drivers.Value {
+			return c.csrfField()
+		},
+	}
+
+	c.addFlashHelpers(templateHelpers)
+	templateData[drivers.HelpersKey] = templateHelpers
+
+	return templateData
+}
+
+// Logging
+
+// Logger returns a logger for the request.
+// Its entries contain the method, path and route, and the fields added with AddLogFields.
+func (c *Context) Logger() LoggerInterface {
+	if c.logger != nil {
+		return c.logger
+	}
+
+	c.logger = c.a.logger().WithContext(c.request.Context()).WithFields(requestFields(c.request))
+
+	return c.logger
+}
+
+// RequestID returns the ID of the request set by the RequestIDMiddleware, or an empty string.
+func (c *Context) RequestID() string {
+	return RequestIDFromContext(c.request.Context())
+}
+
+// AddLogFields adds fields to the entries of the request logger.
+// The fields are stored in the request context, so loggers created with WithContext from it contain them too.
+func (c *Context) AddLogFields(fields Fields) {
+	c.request = c.request.WithContext(logging.ContextWithFields(c.request.Context(), fields))
+	c.logger = nil
 }
 
 // For middleware
 
+// Next runs the rest of the middleware chain and the handler.
+// Middleware can use it to run code after the handler, the error of the chain is returned.
+// The chain ends once a handler fails or aborts, even when a middleware before it handles the error.
+func (c *Context) Next() error {
+	for c.index < len(c.handlers) {
+		h := c.handlers[c.index]
+		c.index++
+
+		if err := h(c); err != nil {
+			c.index = len(c.handlers)
+
+			return err
+		}
+
+		if c.abort {
+			c.index = len(c.handlers)
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (c *Context) setHandlers(handlers []HandlerFunc) {
+	c.handlers = handlers
+	c.index = 0
+}
+
+func (c *Context) setRequest(r *http.Request) {
+	c.request = r
+}
+
+func (c *Context) setResponseWriter(w http.ResponseWriter) {
+	c.responseWriter = w
+
+	if response, ok := w.(*Response); ok {
+		c.response = response
+	}
+}
+
 // Abort stops the current middleware chain.
 func (c *Context) Abort() {
 	c.abort = true
@@ -0,0 +1,152 @@
+package leopard
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// AllowOrigins are the origins that can make cross-origin requests, defaults to all origins (*).
+	// A * in an origin matches a subdomain, for example https://*.example.com.
+	AllowOrigins []string
+
+	// AllowOriginPatterns are regular expressions for origins that can make cross-origin requests.
+	// The patterns must match the whole origin, https://app\.example\.com does not match https://app.example.com.evil.net.
+	AllowOriginPatterns []string
+
+	// AllowOriginFunc decides if an origin can make cross-origin requests, next to the other options.
+	AllowOriginFunc func(origin string) bool
+
+	// AllowMethods are the methods allowed in preflight requests, defaults to GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowMethods []string
+
+	// AllowHeaders are the request headers allowed in preflight requests, the requested headers are allowed when it is empty.
+	AllowHeaders []string
+
+	// ExposeHeaders are the response headers the browser makes available to scripts.
+	ExposeHeaders []string
+
+	// AllowCredentials allows cookies and authorization headers in cross-origin requests.
+	// The origin of the request is sent back instead of *, browsers reject * with credentials.
+	// It needs explicit origins, CORS panics when it is combined with all origins (*).
+	AllowCredentials bool
+
+	// MaxAge is how long the result of a preflight request can be cached by the browser.
+	MaxAge time.Duration
+}
+
+// CORS adds the Cross-Origin Resource Sharing headers to responses for allowed origins,
+// and answers preflight requests without running the rest of the chain.
+// Add it with app.Use, so it also runs for the OPTIONS requests the router answers automatically.
+func CORS(config CORSConfig) HandlerFunc {
+	if len(config.AllowOrigins) == 0 && len(config.AllowOriginPatterns) == 0 && config.AllowOriginFunc == nil {
+		config.AllowOrigins = []string{"*"}
+	}
+
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = []string{
+			http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+		}
+	}
+
+	allowAll := false
+	var patterns []*regexp.Regexp
+
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			allowAll = true
+		} else if strings.Contains(origin, "*") {
+			pattern := strings.ReplaceAll(regexp.QuoteMeta(origin), `\*`, `[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)*`)
+			patterns = append(patterns, regexp.MustCompile("^"+pattern+"$"))
+		}
+	}
+
+	for _, pattern := range config.AllowOriginPatterns {
+		patterns = append(patterns, regexp.MustCompile("^(?:"+pattern+")$"))
+	}
+
+	allowed := func(origin string) bool {
+		if allowAll {
+			return true
+		}
+
+		for _, o := range config.AllowOrigins {
+			if o == origin {
+				return true
+			}
+		}
+
+		for _, pattern := range patterns {
+			if pattern.MatchString(origin) {
+				return true
+			}
+		}
+
+		return config.AllowOriginFunc != nil && config.AllowOriginFunc(origin)
+	}
+
+	// Sending back any origin with credentials would let every site read the responses of logged in users.
+	if allowAll && config.AllowCredentials {
+		panic("cors: AllowCredentials needs explicit origins, it can not be used with all origins (*)")
+	}
+
+	methods := strings.Join(config.AllowMethods, ", ")
+	headers := strings.Join(config.AllowHeaders, ", ")
+	exposed := strings.Join(config.ExposeHeaders, ", ")
+
+	return func(c ContextInterface) error {
+		header := c.Response().Header()
+		origin := c.GetHeader("Origin")
+		preflight := c.Request().Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		// The response depends on the origin, unless every origin gets the same *.
+		if !allowAll {
+			header.Add("Vary", "Origin")
+		}
+
+		if origin == "" || !allowed(origin) {
+			return c.Next()
+		}
+
+		if allowAll {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposed != "" {
+				header.Set("Access-Control-Expose-Headers", exposed)
+			}
+
+			return c.Next()
+		}
+
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", methods)
+
+		if headers != "" {
+			header.Set("Access-Control-Allow-Headers", headers)
+		} else if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+
+		if config.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+		}
+
+		c.Status(http.StatusNoContent)
+		c.Abort()
+
+		return nil
+	}
+}
@@ -0,0 +1,130 @@
+package leopard_test
+
+import (
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAutomaticOptionsAndHead(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/users", func(c leopard.ContextInterface) error {
+		c.SetHeader("X-Total", "2")
+		_, err := c.WriteString("users")
+
+		return err
+	})
+
+	a.POST("/users", func(c leopard.ContextInterface) {})
+
+	a.AddRoute("OPTIONS", "/custom", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString("custom")
+
+		return err
+	})
+
+	client := a.Test()
+
+	client.OPTIONS("/users").Expect(t).
+		Status(204).
+		Header("Allow", "GET, HEAD, POST, OPTIONS")
+
+	client.OPTIONS("/custom").Expect(t).Status(200).BodyEquals("custom")
+	client.OPTIONS("/missing").Expect(t).Status(404)
+
+	client.HEAD("/users").Expect(t).
+		Status(200).
+		Header("X-Total", "2").
+		BodyEquals("")
+
+	client.DELETE("/users").Expect(t).Status(405)
+}
+
+func TestCORS(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.Use(leopard.CORS(leopard.CORSConfig{
+		AllowOrigins:        []string{"https://app.example.com", "https://*.example.org"},
+		AllowOriginPatterns: []string{`http://localhost:\d+`},
+		AllowHeaders:        []string{"Content-Type", "Authorization"},
+		ExposeHeaders:       []string{"X-Total"},
+		AllowCredentials:    true,
+		MaxAge:              10 * time.Minute,
+	}))
+
+	a.GET("/users", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString("users")
+
+		return err
+	})
+
+	client := a.Test()
+
+	for _, origin := range []string{"https://app.example.com", "https://a.b.example.org", "http://localhost:3000"} {
+		client.GET("/users").WithHeader("Origin", origin).Expect(t).
+			Status(200).
+			Header("Access-Control-Allow-Origin", origin).
+			Header("Access-Control-Allow-Credentials", "true").
+			Header("Access-Control-Expose-Headers", "X-Total").
+			Header("Vary", "Origin").
+			BodyEquals("users")
+	}
+
+	for _, origin := range []string{"https://evil.com", "https://example.org.evil.com", "http://localhost:3000.evil.com"} {
+		client.GET("/users").WithHeader("Origin", origin).Expect(t).
+			Status(200).
+			Header("Access-Control-Allow-Origin", "")
+	}
+
+	response := client.OPTIONS("/users").
+		WithHeader("Origin", "https://app.example.com").
+		WithHeader("Access-Control-Request-Method", "POST").
+		Expect(t).
+		Status(204).
+		Header("Access-Control-Allow-Origin", "https://app.example.com").
+		Header("Access-Control-Allow-Headers", "Content-Type, Authorization").
+		Header("Access-Control-Max-Age", "600").
+		BodyEquals("")
+
+	if !strings.Contains(response.Response.Header.Get("Access-Control-Allow-Methods"), "DELETE") {
+		t.Errorf("expected the allowed methods, got %q", response.Response.Header.Get("Access-Control-Allow-Methods"))
+	}
+}
+
+func TestCORSWildcard(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.Use(leopard.CORS(leopard.CORSConfig{}))
+
+	a.GET("/", func(c leopard.ContextInterface) {})
+
+	a.Test().OPTIONS("/").
+		WithHeader("Origin", "https://anywhere.com").
+		WithHeader("Access-Control-Request-Method", "GET").
+		WithHeader("Access-Control-Request-Headers", "X-Custom").
+		Expect(t).
+		Status(204).
+		Header("Access-Control-Allow-Origin", "*").
+		Header("Access-Control-Allow-Headers", "X-Custom").
+		Header("Vary", "Access-Control-Request-Method")
+}
+
+func TestCORSCredentialsNeedOrigins(t *testing.T) {
+	for _, config := range []leopard.CORSConfig{
+		{AllowCredentials: true},
+		{AllowCredentials: true, AllowOrigins: []string{"https://app.example.com", "*"}},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected CORS to panic for credentials with all origins %v", config.AllowOrigins)
+				}
+			}()
+
+			leopard.CORS(config)
+		}()
+	}
+}
@@ -0,0 +1,276 @@
+package leopard
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"github.com/volix-dev/leopard/helpers"
+	"github.com/volix-dev/leopard/templating/drivers"
+	"html"
+	"net/http"
+	"net/url"
+)
+
+// CSRFExempt skips the CSRF check for a route or a group, for example for webhooks that are authenticated otherwise.
+const CSRFExempt RouteFlag = "csrf_exempt"
+
+// Errors returned by the CSRF middleware, they are responded with a 403.
+var (
+	ErrCSRFToken  = NewHTTPError(http.StatusForbidden, "invalid CSRF token")
+	ErrCSRFOrigin = NewHTTPError(http.StatusForbidden, "cross-origin request")
+)
+
+// CSRFConfig configures the CSRF middleware.
+type CSRFConfig struct {
+	// FieldName is the form field with the token, defaults to _csrf.
+	FieldName string
+
+	// HeaderName is the request header with the token, defaults to X-CSRF-Token.
+	HeaderName string
+
+	// Cookie keeps the token in a signed cookie instead of the session (double submit cookie),
+	// for apps that do not use the Sessions middleware.
+	Cookie bool
+
+	// CookieName is the name of the cookie with the token, defaults to csrf_token.
+	CookieName string
+
+	// Secret signs the cookie, defaults to SESSION_SECRET.
+	Secret string
+
+	// Secure only sends the cookie over https.
+	Secure bool
+
+	// TrustedOrigins are other origins that can send requests, for example https://admin.example.com.
+	// Requests from other origins are rejected when the browser sends an Origin or Referer header.
+	TrustedOrigins []string
+}
+
+// csrfSessionKey is the key of the token in the session.
+const csrfSessionKey = "_csrf_token"
+
+// csrfTokenSize is the size of the token in bytes.
+const csrfTokenSize = 32
+
+type csrfKey struct{}
+
+// csrfState is the token of a request, it is stored in the request context.
+type csrfState struct {
+	config *CSRFConfig
+	token  []byte
+}
+
+// CSRF protects against cross-site request forgery. Requests with unsafe methods, like POST,
+// need the token of the client in the form field or header, and must come from the same origin.
+//
+// The token is kept in the session (synchronizer token), so the Sessions middleware has to run first,
+// or in a signed cookie when Cookie is set. Use Context.CSRFToken or the csrf_token and csrf_field
+// template functions to add it to forms.
+func CSRF(config CSRFConfig) HandlerFunc {
+	if config.FieldName == "" {
+		config.FieldName = "_csrf"
+	}
+
+	if config.HeaderName == "" {
+		config.HeaderName = "X-CSRF-Token"
+	}
+
+	if config.CookieName == "" {
+		config.CookieName = "csrf_token"
+	}
+
+	var secret *cookieSecret
+
+	if config.Cookie {
+		secret = newCookieSecret(config.Secret, "CSRF tokens end")
+	}
+
+	return func(c ContextInterface) error {
+		hc, ok := c.(httpContext)
+
+		if !ok {
+			return c.Next()
+		}
+
+		// The token is created before the handler runs, the response can be written by the time a template needs it.
+		token, err := loadCSRFToken(c, &config, secret)
+
+		if err != nil {
+			return err
+		}
+
+		state := &csrfState{config: &config, token: token}
+		hc.setRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), csrfKey{}, state)))
+
+		switch c.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			return c.Next()
+		}
+
+		if HasRouteFlag(c, CSRFExempt) {
+			return c.Next()
+		}
+
+		if !trustedOrigin(c, config.TrustedOrigins) {
+			return ErrCSRFOrigin
+		}
+
+		submitted := c.GetHeader(config.HeaderName)
+
+		if submitted == "" {
+			// The form is parsed with the limits of the app, later reads of the body use the parsed form.
+			if fc, ok := c.(formContext); ok {
+				if err := fc.parseForm(c.App().bindConfig()); err != nil {
+					return err
+				}
+			}
+
+			submitted = c.ReadFormValue(config.FieldName)
+		}
+
+		if !validCSRFToken(submitted, token) {
+			return ErrCSRFToken
+		}
+
+		return c.Next()
+	}
+}
+
+// loadCSRFToken gets the token of the client from the session or the cookie, a new token is created when it has none.
+func loadCSRFToken(c ContextInterface, config *CSRFConfig, secret *cookieSecret) ([]byte, error) {
+	if !config.Cookie {
+		session := c.Session()
+		encoded := session.GetString(csrfSessionKey)
+
+		if token, err := base64.RawURLEncoding.DecodeString(encoded); err == nil && len(token) == csrfTokenSize {
+			return token, nil
+		}
+
+		token := randomBytes(csrfTokenSize)
+
+		return token, session.Set(csrfSessionKey, base64.RawURLEncoding.EncodeToString(token))
+	}
+
+	if cookie, err := c.GetCookie(config.CookieName); err == nil {
+		if encoded, ok := helpers.Unsign(cookie.Value, secret.get(c)); ok {
+			if token, err := base64.RawURLEncoding.DecodeString(encoded); err == nil && len(token) == csrfTokenSize {
+				return token, nil
+			}
+		}
+	}
+
+	token := randomBytes(csrfTokenSize)
+
+	c.SetResponseCookie(&http.Cookie{
+		Name:     config.CookieName,
+		Value:    helpers.Sign(base64.RawURLEncoding.EncodeToString(token), secret.get(c)),
+		Path:     "/",
+		Secure:   config.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return token, nil
+}
+
+// trustedOrigin checks the Origin header, or the Referer header when there is no Origin,
+// against the host of the request and the trusted origins. Requests without both headers are allowed.
+func trustedOrigin(c ContextInterface, trusted []string) bool {
+	origin := c.GetHeader("Origin")
+
+	if origin == "" {
+		referer := c.GetHeader("Referer")
+
+		if referer == "" {
+			return true
+		}
+
+		u, err := url.Parse(referer)
+
+		if err != nil {
+			return false
+		}
+
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	u, err := url.Parse(origin)
+
+	if err == nil && u.Host != "" && u.Host == c.Request().Host {
+		return true
+	}
+
+	for _, o := range trusted {
+		if o == origin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maskCSRFToken xors the token with a random pad, so the token in the page is different for every response.
+// This prevents attacks that guess the token from the size of compressed responses (BREACH).
+func maskCSRFToken(token []byte) string {
+	pad := randomBytes(len(token))
+	masked := make([]byte, 2*len(token))
+	copy(masked, pad)
+
+	for i := range token {
+		masked[len(token)+i] = pad[i] ^ token[i]
+	}
+
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+// validCSRFToken unmasks the submitted token and compares it with the token of the client.
+func validCSRFToken(submitted string, token []byte) bool {
+	masked, err := base64.RawURLEncoding.DecodeString(submitted)
+
+	if err != nil || len(masked) != 2*len(token) {
+		return false
+	}
+
+	unmasked := make([]byte, len(token))
+
+	for i := range token {
+		unmasked[i] = masked[i] ^ masked[len(token)+i]
+	}
+
+	return subtle.ConstantTimeCompare(unmasked, token) == 1
+}
+
+func randomBytes(n int) []byte {
+	data := make([]byte, n)
+
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
+// CSRFToken gets the token for forms and the X-CSRF-Token header, it needs the CSRF middleware.
+// The token is masked differently on every call, all of them are valid.
+func (c *Context) CSRFToken() string {
+	state, ok := c.request.Context().Value(csrfKey{}).(*csrfState)
+
+	if !ok {
+		return ""
+	}
+
+	return maskCSRFToken(state.token)
+}
+
+// csrfField gets a hidden form field with the token.
+func (c *Context) csrfField() drivers.HTML {
+	state, ok := c.request.Context().Value(csrfKey{}).(*csrfState)
+
+	if !ok {
+		return ""
+	}
+
+	return drivers.HTML(`<input type="hidden" name="` + html.EscapeString(state.config.FieldName) +
+		`" value="` + maskCSRFToken(state.token) + `">`)
+}
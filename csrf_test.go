@@ -0,0 +1,119 @@
+package leopard_test
+
+import (
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"github.com/volix-dev/leopard/templating/drivers/twigDriver"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	templates := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(templates, "form.twig"), []byte(`<form>{{ csrf_field() }}</form>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := leopardtest.New(t,
+		leopard.WithTemplateDriver(twigDriver.NewTwigCompatDriver()),
+		leopard.WithTemplatePath(templates),
+	)
+
+	a.Use(leopard.Sessions(leopard.SessionConfig{Secret: "secret"}))
+	a.Use(leopard.CSRF(leopard.CSRFConfig{TrustedOrigins: []string{"https://admin.example.com"}}))
+
+	ok := func(c leopard.ContextInterface) error {
+		_, err := c.WriteString("ok")
+
+		return err
+	}
+
+	a.GET("/form", func(c leopard.ContextInterface) error {
+		return c.RenderTemplate("form.twig", nil)
+	})
+
+	a.POST("/submit", ok)
+	a.POST("/webhook", ok, leopard.CSRFExempt)
+
+	a.Group("/hooks", func(group leopard.RouteGroup) {
+		group.POST("/github", ok)
+	}, leopard.CSRFExempt)
+
+	client := a.Test()
+	body := client.GET("/form").Expect(t).Status(200).BodyString()
+	match := regexp.MustCompile(`^<form><input type="hidden" name="_csrf" value="([\w-]+)"></form>$`).FindStringSubmatch(body)
+
+	if match == nil {
+		t.Fatalf("expected a hidden field with the token, got %q", body)
+	}
+
+	token := match[1]
+
+	client.POST("/submit").Expect(t).Status(403)
+	client.POST("/submit").WithForm(url.Values{"_csrf": {"invalid"}}).Expect(t).Status(403)
+	client.POST("/submit").WithForm(url.Values{"_csrf": {token}}).Expect(t).Status(200).BodyEquals("ok")
+	client.POST("/submit").WithHeader("X-CSRF-Token", token).Expect(t).Status(200)
+
+	client.POST("/submit").WithHeader("X-CSRF-Token", token).WithHeader("Origin", "https://evil.com").Expect(t).Status(403)
+	client.POST("/submit").WithHeader("X-CSRF-Token", token).WithHeader("Referer", "https://evil.com/page").Expect(t).Status(403)
+	client.POST("/submit").WithHeader("X-CSRF-Token", token).WithHeader("Origin", "http://leopard.test").Expect(t).Status(200)
+	client.POST("/submit").WithHeader("X-CSRF-Token", token).WithHeader("Origin", "https://admin.example.com").Expect(t).Status(200)
+
+	// The token belongs to the session of the client.
+	a.Test().POST("/submit").WithHeader("X-CSRF-Token", token).Expect(t).Status(403)
+
+	a.Test().POST("/webhook").Expect(t).Status(200)
+	a.Test().POST("/hooks/github").Expect(t).Status(200)
+}
+
+func TestCSRFCookie(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.Use(leopard.CSRF(leopard.CSRFConfig{Cookie: true, Secret: "secret"}))
+
+	a.GET("/token", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString(c.CSRFToken())
+
+		return err
+	})
+
+	a.PUT("/users/1", func(c leopard.ContextInterface) {})
+
+	client := a.Test()
+	token := client.GET("/token").Expect(t).Status(200).BodyString()
+
+	client.PUT("/users/1").WithHeader("X-CSRF-Token", token).Expect(t).Status(200)
+	client.PUT("/users/1").Expect(t).Status(403)
+
+	a.Test().PUT("/users/1").WithHeader("X-CSRF-Token", token).Expect(t).Status(403)
+	a.Test().PUT("/users/1").WithHeader("X-CSRF-Token", token).WithCookie("csrf_token", "forged.value").Expect(t).Status(403)
+}
+
+func TestCSRFBodyLimit(t *testing.T) {
+	a := leopardtest.New(t, leopard.WithBindConfig(leopard.BindConfig{MaxBodySize: 160}))
+
+	a.Use(leopard.CSRF(leopard.CSRFConfig{Cookie: true, Secret: "secret"}))
+
+	a.GET("/token", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString(c.CSRFToken())
+
+		return err
+	})
+
+	a.POST("/upload", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString(c.ReadFormValue("data"))
+
+		return err
+	})
+
+	client := a.Test()
+	token := client.GET("/token").Expect(t).Status(200).BodyString()
+
+	client.POST("/upload").WithForm(url.Values{"_csrf": {token}, "data": {"small"}}).Expect(t).Status(200).BodyEquals("small")
+	client.POST("/upload").WithForm(url.Values{"_csrf": {token}, "data": {strings.Repeat("a", 128)}}).Expect(t).Status(413)
+}
@@ -1,29 +1,103 @@
 package defaultlogger
 
-import "github.com/sirupsen/logrus"
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/volix-dev/leopard/logging"
+	"io"
+)
 
 type LogrusLogger struct {
+	entry *logrus.Entry
 }
 
 func New() *LogrusLogger {
-	logrus.SetFormatter(&logrus.TextFormatter{ForceColors: true})
-	logrus.SetLevel(logrus.DebugLevel)
+	logger := logrus.New()
+	logger.SetFormatter(&logrus.TextFormatter{ForceColors: true})
+	logger.SetLevel(logrus.DebugLevel)
 
-	return &LogrusLogger{}
+	return &LogrusLogger{
+		entry: logrus.NewEntry(logger),
+	}
+}
+
+// SetLevel sets the minimum level of the entries that are logged.
+// The level is one of trace, debug, info, warning, error, fatal or panic.
+func (l LogrusLogger) SetLevel(level string) error {
+	parsed, err := logrus.ParseLevel(level)
+
+	if err != nil {
+		return err
+	}
+
+	l.entry.Logger.SetLevel(parsed)
+
+	return nil
+}
+
+// SetFormat sets the format of the entries, it is one of text, logfmt or json.
+// The text format is logfmt with colors.
+func (l LogrusLogger) SetFormat(format string) error {
+	switch format {
+	case "text":
+		l.entry.Logger.SetFormatter(&logrus.TextFormatter{ForceColors: true})
+	case "logfmt":
+		l.entry.Logger.SetFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true})
+	case "json":
+		l.entry.Logger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+
+	return nil
+}
+
+// SetOutput sets the writer the entries are written to.
+func (l LogrusLogger) SetOutput(output io.Writer) {
+	l.entry.Logger.SetOutput(output)
 }
 
 func (l LogrusLogger) Info(arg ...interface{}) {
-	logrus.Info(arg...)
+	l.entry.Info(arg...)
 }
 
 func (l LogrusLogger) Warning(arg ...interface{}) {
-	logrus.Warning(arg...)
+	l.entry.Warning(arg...)
 }
 
 func (l LogrusLogger) Error(arg ...interface{}) {
-	logrus.Error(arg...)
+	l.entry.Error(arg...)
 }
 
 func (l LogrusLogger) Debug(arg ...interface{}) {
-	logrus.Debug(arg...)
+	l.entry.Debug(arg...)
+}
+
+func (l LogrusLogger) Infof(format string, args ...interface{}) {
+	l.entry.Infof(format, args...)
+}
+
+func (l LogrusLogger) Warningf(format string, args ...interface{}) {
+	l.entry.Warningf(format, args...)
+}
+
+func (l LogrusLogger) Errorf(format string, args ...interface{}) {
+	l.entry.Errorf(format, args...)
+}
+
+func (l LogrusLogger) Debugf(format string, args ...interface{}) {
+	l.entry.Debugf(format, args...)
+}
+
+func (l LogrusLogger) WithFields(fields logging.Fields) logging.Logger {
+	return &LogrusLogger{
+		entry: l.entry.WithFields(logrus.Fields(fields)),
+	}
+}
+
+func (l LogrusLogger) WithContext(ctx context.Context) logging.Logger {
+	return &LogrusLogger{
+		entry: l.entry.WithContext(ctx).WithFields(logrus.Fields(logging.FieldsFromContext(ctx))),
+	}
 }
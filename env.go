@@ -1,9 +1,15 @@
 package leopard
 
-import "os"
+import (
+	"fmt"
+	"github.com/joho/godotenv"
+	"os"
+	"strconv"
+	"time"
+)
 
 // GetEnv get a enviroment variable or the default value
-func (a LeopardApp) GetEnv(setting string, defaultValue string) string {
+func (a *LeopardApp) GetEnv(setting string, defaultValue string) string {
 	value := os.Getenv(setting)
 
 	if value == "" {
@@ -13,6 +19,58 @@ func (a LeopardApp) GetEnv(setting string, defaultValue string) string {
 	return value
 }
 
-func (a LeopardApp) GetEnvironment() string {
+func (a *LeopardApp) GetEnvironment() string {
 	return a.GetEnv("LEOPARD_ENV", "DEVELOPMENT")
 }
+
+// loadEnvFile loads the environment file into the environment.
+// When no path is given the default .env file is loaded if it exists.
+func loadEnvFile(path string) error {
+	if path != "" {
+		return godotenv.Load(path)
+	}
+
+	err := godotenv.Load()
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// envDuration reads a duration setting from the environment.
+func envDuration(setting string, defaultValue string) (time.Duration, error) {
+	value := EnvSettingD(setting, defaultValue).GetValue().(string)
+	duration, err := time.ParseDuration(value)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration for %s: %w", setting, err)
+	}
+
+	return duration, nil
+}
+
+// envInt reads an integer setting from the environment.
+func envInt(setting string, defaultValue int) (int, error) {
+	value := EnvSettingD(setting, strconv.Itoa(defaultValue)).GetValue().(string)
+	i, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid number for %s: %w", setting, err)
+	}
+
+	return i, nil
+}
+
+// envBool reads a boolean setting from the environment.
+func envBool(setting string, defaultValue bool) (bool, error) {
+	value := EnvSettingD(setting, strconv.FormatBool(defaultValue)).GetValue().(string)
+	b, err := strconv.ParseBool(value)
+
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean for %s: %w", setting, err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,220 @@
+package leopard
+
+import (
+	"errors"
+	"fmt"
+	"github.com/volix-dev/leopard/helpers"
+	"github.com/volix-dev/leopard/static"
+	"github.com/volix-dev/leopard/templating/drivers"
+	"github.com/volix-dev/leopard/templating/drivers/twigDriver"
+	"io/fs"
+	"net/http"
+	"runtime/debug"
+)
+
+// Sentinel errors that are mapped to a status code by the default error handler.
+// They can be wrapped to add details, for example fmt.Errorf("user %d: %w", id, leopard.ErrNotFound).
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+)
+
+// defaultErrorMappings are the status codes of the sentinel errors.
+var defaultErrorMappings = []errorMapping{
+	{ErrBadRequest, http.StatusBadRequest},
+	{ErrUnauthorized, http.StatusUnauthorized},
+	{ErrForbidden, http.StatusForbidden},
+	{ErrNotFound, http.StatusNotFound},
+	{ErrConflict, http.StatusConflict},
+	{ErrValidation, http.StatusUnprocessableEntity},
+}
+
+type errorMapping struct {
+	target error
+	code   int
+}
+
+// StatusCoder is implemented by errors that know their http status code.
+// The default error handler finds them anywhere in the error chain.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// HTTPError is an error with an http status code.
+// Handlers can pass it to Context.Error or panic with it to respond with that status code.
+type HTTPError struct {
+	// Code is the http status code of the response.
+	Code int
+
+	// Message is shown to the client.
+	Message string
+
+	// Internal is the underlying error, it is never shown to the client.
+	Internal error
+}
+
+// NewHTTPError creates an error with a status code and a message for the client.
+// When the message is empty the status text of the code is used.
+func NewHTTPError(code int, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+
+	return &HTTPError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Internal != nil {
+		return fmt.Sprintf("%d %s: %v", e.Code, e.Message, e.Internal)
+	}
+
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// Unwrap returns the internal error.
+func (e *HTTPError) Unwrap() error {
+	return e.Internal
+}
+
+// WithInternal returns a copy of the error with the internal error set.
+func (e *HTTPError) WithInternal(err error) *HTTPError {
+	return &HTTPError{
+		Code:     e.Code,
+		Message:  e.Message,
+		Internal: err,
+	}
+}
+
+// MapError makes the error handler respond with the status code for errors that match the target with errors.Is.
+// Mappings registered on the app are checked before the sentinel errors of leopard.
+func (a *LeopardApp) MapError(target error, code int) {
+	a.errorMappings = append(a.errorMappings, errorMapping{target, code})
+}
+
+// ErrorHandlerFunc writes the response for an error.
+type ErrorHandlerFunc func(c ContextInterface, err error) error
+
+// DefaultErrorHandler responds with an html error page to browsers and with json to all other clients.
+// The stack trace is only included in the DEVELOPMENT environment,
+// in other environments only the message of an HTTPError is shown, other errors get the status text of their code.
+func DefaultErrorHandler(c ContextInterface, err error) error {
+	httpErr, public := c.App().toHTTPError(err)
+	development := c.App().GetEnvironment() == "DEVELOPMENT"
+
+	if httpErr.Code >= http.StatusInternalServerError {
+		c.Logger().Error(err)
+	}
+
+	// The status code can not be changed anymore, writing the error would only corrupt the body.
+	if c.Response().Written() {
+		return nil
+	}
+
+	message := httpErr.Message
+
+	if !development && !public {
+		message = http.StatusText(httpErr.Code)
+	}
+
+	data := map[string]interface{}{
+		"message": message,
+	}
+
+	var detailer ErrorDetailer
+
+	if errors.As(err, &detailer) {
+		data["errors"] = detailer.ErrorDetails()
+	}
+
+	if id := c.RequestID(); id != "" {
+		data["request_id"] = id
+	}
+
+	if development {
+		data["stacktrace"] = helpers.SerializeStack(debug.Stack())
+	}
+
+	if c.Accepts("application/json", "text/html") == "text/html" {
+		return c.App().renderErrorPage(c, httpErr.Code, data)
+	}
+
+	return c.JsonStatus(httpErr.Code, data)
+}
+
+// toHTTPError finds the status code of the error.
+// It looks for an HTTPError or StatusCoder in the error chain, then for mapped errors.
+// Other errors are converted to an internal server error with the error as internal error.
+// It returns true when the message is meant for the client, which is only the case for an HTTPError,
+// the messages of other errors can contain internal details like fmt.Errorf("query users: %w", ErrNotFound).
+func (a *LeopardApp) toHTTPError(err error) (*HTTPError, bool) {
+	var httpErr *HTTPError
+
+	if errors.As(err, &httpErr) {
+		return httpErr, true
+	}
+
+	var coder StatusCoder
+
+	if errors.As(err, &coder) {
+		return &HTTPError{Code: coder.StatusCode(), Message: err.Error(), Internal: err}, false
+	}
+
+	var mappings []errorMapping
+
+	if a != nil {
+		mappings = a.errorMappings
+	}
+
+	for _, mapping := range append(mappings, defaultErrorMappings...) {
+		if errors.Is(err, mapping.target) {
+			return &HTTPError{Code: mapping.code, Message: err.Error(), Internal: err}, false
+		}
+	}
+
+	return &HTTPError{
+		Code:     http.StatusInternalServerError,
+		Message:  err.Error(),
+		Internal: err,
+	}, false
+}
+
+// renderErrorPage renders the embedded error page template.
+func (a *LeopardApp) renderErrorPage(c ContextInterface, status int, data map[string]interface{}) error {
+	a.errorPagesOnce.Do(func() {
+		templates, err := fs.Sub(static.FS, "embedded/templates")
+
+		if err != nil {
+			a.errorPagesErr = err
+			return
+		}
+
+		a.errorPages = twigDriver.NewTwigCompatDriver()
+		a.errorPagesErr = a.errorPages.LoadFS(templates, a.router)
+	})
+
+	if a.errorPagesErr != nil {
+		return a.errorPagesErr
+	}
+
+	values := map[string]drivers.Value{
+		"debug":      data["stacktrace"] != nil,
+		"status":     status,
+		"statusText": http.StatusText(status),
+	}
+
+	for key, value := range data {
+		values[key] = value
+	}
+
+	c.SetHeader("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+
+	return a.errorPages.RenderTemplate("errorpage.twig.html", c.ResponseWriter(), values)
+}
@@ -0,0 +1,158 @@
+package leopard_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPErrorStatus(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/returned", func(c leopard.ContextInterface) {
+		_ = c.Error(leopard.NewHTTPError(404, "user not found"))
+	})
+
+	a.GET("/panicked", func(c leopard.ContextInterface) {
+		panic(leopard.NewHTTPError(403, ""))
+	})
+
+	client := a.Test()
+
+	client.GET("/returned").Expect(t).Status(404).JSONPath("message", "user not found")
+	client.GET("/panicked").Expect(t).Status(403).JSONPath("message", "Forbidden")
+}
+
+func TestErrorPageForBrowsers(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/missing", func(c leopard.ContextInterface) {
+		_ = c.Error(leopard.NewHTTPError(404, "<b>missing</b>"))
+	})
+
+	a.Test().GET("/missing").
+		WithHeader("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8").
+		Expect(t).
+		Status(404).
+		Header("Content-Type", "text/html; charset=utf-8").
+		BodyContains("404 Not Found").
+		BodyContains("&lt;b&gt;missing&lt;/b&gt;").
+		BodyContains("goroutine")
+}
+
+func TestStackTraceOnlyInDevelopment(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/error", func(c leopard.ContextInterface) {
+		_ = c.Error(errors.New("database password is hunter2"))
+	})
+
+	client := a.Test()
+
+	if body := client.GET("/error").Expect(t).Status(500).BodyString(); !strings.Contains(body, "stacktrace") {
+		t.Errorf("expected a stack trace in development, got %s", body)
+	}
+
+	t.Setenv("LEOPARD_ENV", "PRODUCTION")
+
+	body := client.GET("/error").Expect(t).Status(500).JSONPath("message", "Internal Server Error").BodyString()
+
+	if strings.Contains(body, "stacktrace") || strings.Contains(body, "hunter2") {
+		t.Errorf("expected no internal details in production, got %s", body)
+	}
+}
+
+func TestCustomErrorHandler(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.ErrorHandler = func(c leopard.ContextInterface, err error) error {
+		return c.JsonStatus(418, map[string]string{"error": err.Error()})
+	}
+
+	a.GET("/panic", func(c leopard.ContextInterface) {
+		panic("teapot")
+	})
+
+	a.Test().GET("/panic").Expect(t).Status(418).JSONPath("error", "teapot")
+}
+
+type teapotError struct{}
+
+func (teapotError) Error() string {
+	return "short and stout"
+}
+
+func (teapotError) StatusCode() int {
+	return 418
+}
+
+var errPaymentRequired = errors.New("payment required")
+
+func TestReturnedErrorsAreMapped(t *testing.T) {
+	a := leopardtest.New(t)
+	a.MapError(errPaymentRequired, 402)
+
+	a.GET("/users/{id}", func(c leopard.ContextInterface) error {
+		return fmt.Errorf("user %s: %w", c.GetParam("id"), leopard.ErrNotFound)
+	})
+
+	a.GET("/teapot", func(c leopard.ContextInterface) error {
+		return fmt.Errorf("brewing: %w", teapotError{})
+	})
+
+	a.GET("/pay", func(c leopard.ContextInterface) error {
+		return errPaymentRequired
+	})
+
+	a.GET("/ok", func(c leopard.ContextInterface) error {
+		return c.Json(map[string]string{"status": "ok"})
+	})
+
+	client := a.Test()
+
+	client.GET("/users/1").Expect(t).Status(404).JSONPath("message", "user 1: not found")
+	client.GET("/teapot").Expect(t).Status(418).JSONPath("message", "brewing: short and stout")
+	client.GET("/pay").Expect(t).Status(402)
+	client.GET("/ok").Expect(t).Status(200).JSONPath("status", "ok")
+
+	// The messages of wrapped errors can contain internal details.
+	t.Setenv("LEOPARD_ENV", "PRODUCTION")
+
+	client.GET("/users/1").Expect(t).Status(404).JSONPath("message", "Not Found")
+	client.GET("/teapot").Expect(t).Status(418).JSONPath("message", "I'm a teapot")
+}
+
+func TestMiddlewareErrorsStopTheChain(t *testing.T) {
+	a := leopardtest.New(t)
+
+	auth := func(c leopard.ContextInterface) error {
+		if c.GetHeader("Authorization") == "" {
+			return leopard.ErrUnauthorized
+		}
+
+		return nil
+	}
+
+	a.GET("/secret", func(c leopard.ContextInterface) {
+		_, _ = c.WriteString("secret")
+	}, auth)
+
+	client := a.Test()
+
+	client.GET("/secret").Expect(t).Status(401)
+	client.GET("/secret").WithHeader("Authorization", "yes").Expect(t).Status(200).BodyEquals("secret")
+}
+
+func TestPanicAfterWritingKeepsTheResponse(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/partial", func(c leopard.ContextInterface) {
+		_, _ = c.WriteString("partial")
+		panic("oops")
+	})
+
+	a.Test().GET("/partial").Expect(t).Status(200).BodyEquals("partial")
+}
@@ -0,0 +1,102 @@
+package leopard
+
+import (
+	"net/http"
+	"strings"
+)
+
+// fallback handles the requests under a path prefix that have no matching route.
+type fallback struct {
+	prefix     string
+	handler    HandlerFunc
+	middleware []HandlerFunc
+}
+
+// NotFound sets the handler for requests that match no route.
+// It runs after the global middleware, by default a 404 error is passed to the ErrorHandler,
+// which responds with the error page or json depending on the client.
+func (a *LeopardApp) NotFound(h Handler) {
+	a.notFound = setFallback(a.notFound, fallback{handler: toHandlerFunc(h)})
+}
+
+// MethodNotAllowed sets the handler for requests that match the path of a route but not its method.
+// The Allow header is set before the handler runs, by default a 405 error is passed to the ErrorHandler.
+func (a *LeopardApp) MethodNotAllowed(h Handler) {
+	a.methodNotAllowed = setFallback(a.methodNotAllowed, fallback{handler: toHandlerFunc(h)})
+}
+
+// NotFound sets the handler for requests under the prefix of the group that match no route,
+// for example to always respond with json for an api. The middleware of the group runs first.
+func (r RouteGroup) NotFound(h Handler) {
+	r.app.notFound = setFallback(r.app.notFound, r.fallback(h))
+}
+
+// MethodNotAllowed sets the handler for requests under the prefix of the group that match the path of a route but not its method.
+func (r RouteGroup) MethodNotAllowed(h Handler) {
+	r.app.methodNotAllowed = setFallback(r.app.methodNotAllowed, r.fallback(h))
+}
+
+func (r RouteGroup) fallback(h Handler) fallback {
+	return fallback{
+		prefix:     strings.TrimSuffix(r.app.withPrefix(r.prefix), "/"),
+		handler:    toHandlerFunc(h),
+		middleware: r.middleware,
+	}
+}
+
+// setFallback adds the fallback, replacing the one with the same prefix.
+func setFallback(fallbacks []fallback, f fallback) []fallback {
+	for i, existing := range fallbacks {
+		if existing.prefix == f.prefix {
+			fallbacks[i] = f
+
+			return fallbacks
+		}
+	}
+
+	return append(fallbacks, f)
+}
+
+// findFallback gets the fallback with the longest prefix that matches the path.
+func findFallback(fallbacks []fallback, p string) (fallback, bool) {
+	var found fallback
+	ok := false
+
+	for _, f := range fallbacks {
+		matches := f.prefix == "" || p == f.prefix || strings.HasPrefix(p, f.prefix+"/")
+
+		if matches && (!ok || len(f.prefix) > len(found.prefix)) {
+			found, ok = f, true
+		}
+	}
+
+	return found, ok
+}
+
+// serveNotFound runs the not found handler for the request.
+func (a *LeopardApp) serveNotFound(w http.ResponseWriter, r *http.Request) {
+	f, ok := findFallback(a.notFound, r.URL.Path)
+
+	if !ok {
+		f.handler = func(c ContextInterface) error {
+			return NewHTTPError(http.StatusNotFound, "")
+		}
+	}
+
+	a.serveChain(w, r, joinHandlers(a.middleware, f.middleware, []HandlerFunc{f.handler}))
+}
+
+// serveMethodNotAllowed runs the method not allowed handler for the request.
+func (a *LeopardApp) serveMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	f, ok := findFallback(a.methodNotAllowed, r.URL.Path)
+
+	if !ok {
+		f.handler = func(c ContextInterface) error {
+			return NewHTTPError(http.StatusMethodNotAllowed, "")
+		}
+	}
+
+	w.Header().Set("Allow", strings.Join(a.allowedMethods(r), ", "))
+
+	a.serveChain(w, r, joinHandlers(a.middleware, f.middleware, []HandlerFunc{f.handler}))
+}
@@ -0,0 +1,82 @@
+package leopard_test
+
+import (
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"net/http"
+	"testing"
+)
+
+func TestDefaultFallbacks(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.Use(func(c leopard.ContextInterface) {
+		c.SetHeader("X-Middleware", "ran")
+	})
+
+	a.GET("/users", func(c leopard.ContextInterface) {})
+
+	client := a.Test()
+
+	client.GET("/missing").WithHeader("Accept", "application/json").Expect(t).
+		Status(404).
+		Header("X-Middleware", "ran").
+		JSONPath("message", "Not Found")
+
+	client.GET("/missing").WithHeader("Accept", "text/html").Expect(t).
+		Status(404).
+		Header("Content-Type", "text/html; charset=utf-8")
+
+	client.DELETE("/users").WithHeader("Accept", "application/json").Expect(t).
+		Status(405).
+		Header("Allow", "GET, HEAD, OPTIONS").
+		Header("X-Middleware", "ran").
+		JSONPath("message", "Method Not Allowed")
+}
+
+func TestCustomFallbacks(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/users", func(c leopard.ContextInterface) {})
+
+	a.NotFound(func(c leopard.ContextInterface) error {
+		c.Status(http.StatusNotFound)
+		_, err := c.WriteString("nothing here")
+
+		return err
+	})
+
+	a.MethodNotAllowed(func(c leopard.ContextInterface) error {
+		c.Status(http.StatusMethodNotAllowed)
+		_, err := c.WriteString("allowed: " + c.Response().Header().Get("Allow"))
+
+		return err
+	})
+
+	a.Group("/api", func(group leopard.RouteGroup) {
+		group.Use(func(c leopard.ContextInterface) {
+			c.SetHeader("X-Api", "1")
+		})
+
+		group.GET("/users", func(c leopard.ContextInterface) {})
+
+		group.NotFound(func(c leopard.ContextInterface) error {
+			c.Status(http.StatusNotFound)
+
+			return c.Json(map[string]string{"error": "no such endpoint"})
+		})
+	})
+
+	client := a.Test()
+
+	client.GET("/missing").Expect(t).Status(404).BodyEquals("nothing here")
+	client.POST("/users").Expect(t).Status(405).BodyEquals("allowed: GET, HEAD, OPTIONS")
+
+	client.GET("/api/missing").WithHeader("Accept", "text/html").Expect(t).
+		Status(404).
+		Header("X-Api", "1").
+		JSONPath("error", "no such endpoint")
+
+	client.GET("/apis").Expect(t).Status(404).BodyEquals("nothing here")
+	client.POST("/api/users").Expect(t).Status(405).BodyEquals("allowed: GET, HEAD, OPTIONS")
+}
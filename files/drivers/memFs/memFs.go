@@ -0,0 +1,359 @@
+package memFs
+
+import (
+	"bytes"
+	"github.com/volix-dev/leopard/files"
+	"io"
+	"io/fs"
+	path2 "path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	files.Register("memory", func(config any) (files.Driver, error) {
+		return New(), nil
+	})
+}
+
+// MemFs is a file driver that keeps all files in memory.
+// It is intended for tests, everything is lost when the program exits.
+type MemFs struct {
+	lock  sync.RWMutex
+	files map[string]*memFile
+	dirs  map[string]time.Time
+}
+
+type memFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// New creates an empty in memory file driver.
+func New() *MemFs {
+	return &MemFs{
+		files: make(map[string]*memFile),
+		dirs:  map[string]time.Time{".": time.Now()},
+	}
+}
+
+func clean(path string) string {
+	return strings.TrimPrefix(path2.Clean("/"+path), "/")
+}
+
+func notExist(op string, path string) error {
+	return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+}
+
+func (m *MemFs) Create(path string) (files.File, error) {
+	if err := m.WriteFile(path, nil); err != nil {
+		return nil, err
+	}
+
+	return &File{driver: m, path: clean(path), writing: true}, nil
+}
+
+func (m *MemFs) Open(path string) (files.File, error) {
+	data, err := m.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{driver: m, path: clean(path), reader: bytes.NewReader(data)}, nil
+}
+
+func (m *MemFs) Remove(path string) error {
+	return m.RemoveFile(path)
+}
+
+func (m *MemFs) Rename(oldPath, newPath string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	file, ok := m.files[clean(oldPath)]
+
+	if !ok {
+		return notExist("rename", oldPath)
+	}
+
+	delete(m.files, clean(oldPath))
+	m.files[clean(newPath)] = file
+
+	return nil
+}
+
+func (m *MemFs) Stat(path string) (fs.FileInfo, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	name := clean(path)
+
+	if file, ok := m.files[name]; ok {
+		return &FileInfo{name: path2.Base(name), size: int64(len(file.data)), modTime: file.modTime}, nil
+	}
+
+	if modTime, ok := m.dirs[name]; ok {
+		return &FileInfo{name: path2.Base(name), modTime: modTime, dir: true}, nil
+	}
+
+	return nil, notExist("stat", path)
+}
+
+func (m *MemFs) MakeDir(path string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.dirs[clean(path)] = time.Now()
+
+	return nil
+}
+
+func (m *MemFs) RemoveDir(path string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	name := clean(path)
+
+	if _, ok := m.dirs[name]; !ok {
+		return notExist("remove", path)
+	}
+
+	delete(m.dirs, name)
+
+	return nil
+}
+
+func (m *MemFs) RenameDir(oldPath, newPath string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	oldName, newName := clean(oldPath), clean(newPath)
+	modTime, ok := m.dirs[oldName]
+
+	if !ok {
+		return notExist("rename", oldPath)
+	}
+
+	delete(m.dirs, oldName)
+	m.dirs[newName] = modTime
+
+	for name, file := range m.files {
+		if strings.HasPrefix(name, oldName+"/") {
+			delete(m.files, name)
+			m.files[newName+strings.TrimPrefix(name, oldName)] = file
+		}
+	}
+
+	return nil
+}
+
+func (m *MemFs) StatDir(path string) (fs.FileInfo, error) {
+	return m.Stat(path)
+}
+
+func (m *MemFs) ListDir(path string) ([]fs.DirEntry, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	dir := clean(path)
+	prefix := dir + "/"
+
+	if dir == "." || dir == "" {
+		prefix = ""
+	}
+
+	entries := make(map[string]fs.DirEntry)
+
+	for name, file := range m.files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(name, prefix)
+
+		if i := strings.Index(rest, "/"); i >= 0 {
+			entries[rest[:i]] = &FileInfo{name: rest[:i], dir: true, modTime: file.modTime}
+			continue
+		}
+
+		entries[rest] = &FileInfo{name: rest, size: int64(len(file.data)), modTime: file.modTime}
+	}
+
+	list := make([]fs.DirEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+
+	return list, nil
+}
+
+func (m *MemFs) ReadFile(path string) ([]byte, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	file, ok := m.files[clean(path)]
+
+	if !ok {
+		return nil, notExist("open", path)
+	}
+
+	return append([]byte(nil), file.data...), nil
+}
+
+func (m *MemFs) WriteFile(path string, data []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.files[clean(path)] = &memFile{
+		data:    append([]byte(nil), data...),
+		modTime: time.Now(),
+	}
+
+	return nil
+}
+
+func (m *MemFs) ReadFileStream(path string) (io.ReadCloser, error) {
+	return m.Open(path)
+}
+
+func (m *MemFs) WriteFileStream(path string, data io.Reader) error {
+	content, err := io.ReadAll(data)
+
+	if err != nil {
+		return err
+	}
+
+	return m.WriteFile(path, content)
+}
+
+func (m *MemFs) RemoveFile(path string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.files[clean(path)]; !ok {
+		return notExist("remove", path)
+	}
+
+	delete(m.files, clean(path))
+
+	return nil
+}
+
+func (m *MemFs) FileSize(path string) (int64, error) {
+	info, err := m.Stat(path)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+func (m *MemFs) FileModTime(path string) (int64, error) {
+	info, err := m.Stat(path)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return info.ModTime().Unix(), nil
+}
+
+// File is a file opened from a MemFs.
+// Writes are buffered and stored when the file is closed.
+type File struct {
+	driver  *MemFs
+	path    string
+	reader  *bytes.Reader
+	buffer  bytes.Buffer
+	writing bool
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	if f.reader == nil {
+		return 0, io.EOF
+	}
+
+	return f.reader.Read(p)
+}
+
+// Seek sets the offset for the next Read.
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	if f.reader == nil {
+		return 0, nil
+	}
+
+	return f.reader.Seek(offset, whence)
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.writing = true
+
+	return f.buffer.Write(p)
+}
+
+func (f *File) Close() error {
+	if !f.writing {
+		return nil
+	}
+
+	f.writing = false
+
+	return f.driver.WriteFile(f.path, f.buffer.Bytes())
+}
+
+func (f *File) Stat() (fs.FileInfo, error) {
+	return f.driver.Stat(f.path)
+}
+
+// FileInfo describes a file or directory in a MemFs.
+type FileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (f *FileInfo) Name() string {
+	return f.name
+}
+
+func (f *FileInfo) Size() int64 {
+	return f.size
+}
+
+func (f *FileInfo) Mode() fs.FileMode {
+	if f.dir {
+		return fs.ModeDir | 0755
+	}
+
+	return 0644
+}
+
+func (f *FileInfo) ModTime() time.Time {
+	return f.modTime
+}
+
+func (f *FileInfo) IsDir() bool {
+	return f.dir
+}
+
+func (f *FileInfo) Sys() any {
+	return nil
+}
+
+func (f *FileInfo) Type() fs.FileMode {
+	return f.Mode().Type()
+}
+
+func (f *FileInfo) Info() (fs.FileInfo, error) {
+	return f, nil
+}
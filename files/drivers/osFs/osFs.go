@@ -84,6 +84,12 @@ func (o *OsFs) ReadFileStream(path string) (io.ReadCloser, error) {
 }
 
 func (o *OsFs) WriteFileStream(path string, data io.Reader) error {
+	err := os.MkdirAll(path2.Dir(o.addRoot(path)), 0755)
+
+	if err != nil {
+		return err
+	}
+
 	create, err := os.Create(o.addRoot(path))
 
 	if err != nil {
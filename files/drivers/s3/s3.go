@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/volix-dev/leopard/files"
@@ -103,7 +104,7 @@ func (s *S3Driver) Stat(path string) (fs.FileInfo, error) {
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, pathError("stat", path, err)
 	}
 
 	parts := strings.Split(path, "/")
@@ -199,10 +200,17 @@ func (s *S3Driver) ReadFileStream(path string) (io.ReadCloser, error) {
 }
 
 func (s *S3Driver) WriteFileStream(path string, data io.Reader) error {
+	// Seekable readers, like uploaded files, let the sdk find the content length.
+	body, ok := data.(io.ReadSeeker)
+
+	if !ok {
+		body = ReaderConverter{reader: data}
+	}
+
 	_, err := s.client.PutObject(&s3.PutObjectInput{
 		Bucket: aws.String(s.bucket),
 		Key:    aws.String(path),
-		Body:   ReaderConverter{reader: data},
+		Body:   body,
 	})
 
 	return err
@@ -292,7 +300,7 @@ func (s *S3File) Stat() (fs.FileInfo, error) {
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, pathError("stat", s.path, err)
 	}
 
 	parts := strings.Split(s.path, "/")
@@ -307,6 +315,17 @@ func (s *S3File) Stat() (fs.FileInfo, error) {
 	}, nil
 }
 
+// pathError converts the errors of missing objects to fs.ErrNotExist, so they can be checked with errors.Is.
+func pathError(op string, path string, err error) error {
+	var awsErr awserr.Error
+
+	if errors.As(err, &awsErr) && (awsErr.Code() == "NotFound" || awsErr.Code() == s3.ErrCodeNoSuchKey) {
+		return &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+
+	return err
+}
+
 type S3FileInfo struct {
 	object *s3.Object
 }
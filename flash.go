@@ -0,0 +1,343 @@
+package leopard
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/volix-dev/leopard/helpers"
+	"github.com/volix-dev/leopard/sessions"
+	"github.com/volix-dev/leopard/templating/drivers"
+	"net/http"
+	"strings"
+)
+
+// FlashConfig configures the Flashes middleware.
+type FlashConfig struct {
+	// Cookie keeps the flash data in a signed cookie instead of the session,
+	// for apps that do not use the Sessions middleware.
+	Cookie bool
+
+	// CookieName is the name of the cookie with the flash data, defaults to leopard_flash.
+	CookieName string
+
+	// Secret signs the cookie, defaults to SESSION_SECRET.
+	Secret string
+
+	// Secure only sends the cookie over https.
+	Secure bool
+
+	// ExceptInput are the form fields that are never kept by WithInput,
+	// defaults to password, password_confirmation and _csrf.
+	ExceptInput []string
+}
+
+// flashSessionKey is the key of the flash data in the session.
+const flashSessionKey = "_flash"
+
+// ErrFlashTooLarge is logged when the flash data does not fit in the cookie, the data is dropped then.
+var ErrFlashTooLarge = errors.New("leopard: the flash data is too large for a cookie")
+
+// maxFlashCookieSize leaves room for the name and the attributes of the cookie, browsers allow 4096 bytes.
+const maxFlashCookieSize = 3800
+
+type flashKey struct{}
+
+// flashData is what a request passes to the next one.
+type flashData struct {
+	Messages map[string][]string `json:"messages,omitempty"`
+	Input    map[string][]string `json:"input,omitempty"`
+	Errors   map[string][]string `json:"errors,omitempty"`
+}
+
+func (d *flashData) empty() bool {
+	return len(d.Messages) == 0 && len(d.Input) == 0 && len(d.Errors) == 0
+}
+
+// flashState is the flash data of a request, it is stored in the request context.
+type flashState struct {
+	config   *FlashConfig
+	session  *sessions.Session
+	incoming flashData
+	outgoing flashData
+}
+
+// changed writes the outgoing data to the session right away,
+// the session is saved before the response is written and could miss later changes.
+func (s *flashState) changed() error {
+	if s.session == nil {
+		return nil
+	}
+
+	return s.session.Set(flashSessionKey, s.outgoing)
+}
+
+// Flashes keeps messages, form input and errors for the next request of the client, which is usually
+// the page a form redirects to. They are removed once that request is done.
+// The data is kept in the session, so the Sessions middleware has to run first, or in a signed cookie when Cookie is set.
+//
+// Use Context.Flash, Context.WithInput and Context.WithErrors to keep data, and Context.Flashes, Context.Old
+// and Context.FieldErrors or the flashes, old and errors template functions to read it.
+func Flashes(config FlashConfig) HandlerFunc {
+	if config.CookieName == "" {
+		config.CookieName = "leopard_flash"
+	}
+
+	if config.ExceptInput == nil {
+		config.ExceptInput = []string{"password", "password_confirmation", "_csrf"}
+	}
+
+	var secret *cookieSecret
+
+	if config.Cookie {
+		secret = newCookieSecret(config.Secret, "flash messages are lost")
+	}
+
+	return func(c ContextInterface) error {
+		hc, ok := c.(httpContext)
+
+		if !ok {
+			return c.Next()
+		}
+
+		state := &flashState{config: &config}
+
+		var key []byte
+
+		if !config.Cookie {
+			state.session = c.Session()
+
+			if _, err := state.session.Pull(flashSessionKey, &state.incoming); err != nil {
+				return err
+			}
+		} else {
+			key = secret.get(c)
+
+			if cookie, err := c.GetCookie(config.CookieName); err == nil {
+				if encoded, ok := helpers.Unsign(cookie.Value, key); ok {
+					if data, err := base64.RawURLEncoding.DecodeString(encoded); err == nil {
+						_ = json.Unmarshal(data, &state.incoming)
+					}
+				}
+
+				c.SetResponseCookie(&http.Cookie{Name: config.CookieName, Path: "/", MaxAge: -1})
+			}
+		}
+
+		hc.setRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), flashKey{}, state)))
+
+		if !config.Cookie {
+			return c.Next()
+		}
+
+		saved := false
+		save := func() {
+			if saved || state.outgoing.empty() {
+				return
+			}
+
+			saved = true
+			data, err := json.Marshal(state.outgoing)
+
+			if err != nil {
+				c.Logger().Error(fmt.Errorf("saving the flash data: %w", err))
+
+				return
+			}
+
+			value := helpers.Sign(base64.RawURLEncoding.EncodeToString(data), key)
+
+			// Browsers drop cookies that are too large without telling anyone.
+			if len(value) > maxFlashCookieSize {
+				c.Logger().Error(fmt.Errorf("saving the flash data: %w", ErrFlashTooLarge))
+
+				return
+			}
+
+			c.SetResponseCookie(&http.Cookie{
+				Name:     config.CookieName,
+				Value:    value,
+				Path:     "/",
+				Secure:   config.Secure,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+
+		c.Response().Before(save)
+
+		err := c.Next()
+		save()
+
+		return err
+	}
+}
+
+// flash gets the flash state of the request, it is nil without the Flashes middleware.
+func (c *Context) flash() *flashState {
+	state, _ := c.request.Context().Value(flashKey{}).(*flashState)
+
+	return state
+}
+
+// mustFlash gets the flash state of the request for changes, they would be lost without the Flashes middleware.
+func (c *Context) mustFlash() *flashState {
+	state := c.flash()
+
+	if state == nil {
+		panic("leopard: flash data needs the Flashes middleware")
+	}
+
+	return state
+}
+
+// Flash keeps a message for the next request, for example Flash("success", "The post was saved.").
+func (c *Context) Flash(key string, message string) {
+	state := c.mustFlash()
+
+	if state.outgoing.Messages == nil {
+		state.outgoing.Messages = map[string][]string{}
+	}
+
+	state.outgoing.Messages[key] = append(state.outgoing.Messages[key], message)
+
+	if err := state.changed(); err != nil {
+		c.Logger().Error(fmt.Errorf("saving the flash data: %w", err))
+	}
+}
+
+// WithInput keeps the form values for the next request, so a form that failed can be filled in again.
+// Passwords and the CSRF token are left out, see FlashConfig.ExceptInput.
+func (c *Context) WithInput(input map[string][]string) {
+	state := c.mustFlash()
+
+	if state.outgoing.Input == nil {
+		state.outgoing.Input = map[string][]string{}
+	}
+
+	for field, values := range input {
+		if !containsFold(state.config.ExceptInput, field) {
+			state.outgoing.Input[field] = values
+		}
+	}
+
+	if err := state.changed(); err != nil {
+		c.Logger().Error(fmt.Errorf("saving the flash data: %w", err))
+	}
+}
+
+// WithErrors keeps the error messages of the fields for the next request.
+// The fields of a ValidationError or BindingError are kept by their name, other errors are kept as the field "".
+func (c *Context) WithErrors(err error) {
+	if err == nil {
+		return
+	}
+
+	state := c.mustFlash()
+
+	if state.outgoing.Errors == nil {
+		state.outgoing.Errors = map[string][]string{}
+	}
+
+	var validationErr *ValidationError
+	var bindingErr *BindingError
+
+	switch {
+	case errors.As(err, &validationErr):
+		for _, field := range validationErr.Errors {
+			state.outgoing.Errors[field.Field] = append(state.outgoing.Errors[field.Field], field.Message)
+		}
+
+	case errors.As(err, &bindingErr):
+		for _, field := range bindingErr.Fields {
+			state.outgoing.Errors[field.Field] = append(state.outgoing.Errors[field.Field], field.Message)
+		}
+
+	default:
+		state.outgoing.Errors[""] = append(state.outgoing.Errors[""], err.Error())
+	}
+
+	if err := state.changed(); err != nil {
+		c.Logger().Error(fmt.Errorf("saving the flash data: %w", err))
+	}
+}
+
+// Flashes gets the messages kept for this request by the previous one.
+func (c *Context) Flashes(key string) []string {
+	if state := c.flash(); state != nil {
+		return state.incoming.Messages[key]
+	}
+
+	return nil
+}
+
+// Old gets the form value kept for this request by the previous one.
+func (c *Context) Old(field string) string {
+	if state := c.flash(); state != nil && len(state.incoming.Input[field]) > 0 {
+		return state.incoming.Input[field][0]
+	}
+
+	return ""
+}
+
+// FieldErrors gets the error messages of the field kept for this request by the previous one.
+func (c *Context) FieldErrors(field string) []string {
+	if state := c.flash(); state != nil {
+		return state.incoming.Errors[field]
+	}
+
+	return nil
+}
+
+// addFlashHelpers adds the flashes, old and errors template functions.
+// Without arguments flashes and errors return the data of all keys.
+func (c *Context) addFlashHelpers(templateHelpers drivers.Helpers) {
+	var incoming flashData
+
+	if state := c.flash(); state != nil {
+		incoming = state.incoming
+	}
+
+	templateHelpers["flashes"] = func(args ...drivers.Value) drivers.Value {
+		if len(args) == 0 {
+			return incoming.Messages
+		}
+
+		return incoming.Messages[fmt.Sprint(args[0])]
+	}
+
+	templateHelpers["old"] = func(args ...drivers.Value) drivers.Value {
+		if len(args) == 0 {
+			return ""
+		}
+
+		if values := incoming.Input[fmt.Sprint(args[0])]; len(values) > 0 {
+			return values[0]
+		}
+
+		if len(args) > 1 {
+			return args[1]
+		}
+
+		return ""
+	}
+
+	templateHelpers["errors"] = func(args ...drivers.Value) drivers.Value {
+		if len(args) == 0 {
+			return incoming.Errors
+		}
+
+		return incoming.Errors[fmt.Sprint(args[0])]
+	}
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
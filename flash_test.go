@@ -0,0 +1,153 @@
+package leopard_test
+
+import (
+	"bytes"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/defaultlogger"
+	"github.com/volix-dev/leopard/leopardtest"
+	"github.com/volix-dev/leopard/templating/drivers/twigDriver"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const flashTemplate = `{% for message in flashes('success') %}<p>{{ message }}</p>{% endfor %}` +
+	`<input name="email" value="{{ old('email') }}"><input name="password" value="{{ old('password') }}">` +
+	`{% for error in errors('email') %}<span>{{ error }}</span>{% endfor %}`
+
+func flashApp(t *testing.T, config leopard.FlashConfig) *leopardtest.App {
+	templates := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(templates, "register.twig"), []byte(flashTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := leopardtest.New(t,
+		leopard.WithTemplateDriver(twigDriver.NewTwigCompatDriver()),
+		leopard.WithTemplatePath(templates),
+	)
+
+	if !config.Cookie {
+		a.Use(leopard.Sessions(leopard.SessionConfig{Secret: "secret"}))
+	}
+
+	a.Use(leopard.Flashes(config))
+
+	a.GET("/register", func(c leopard.ContextInterface) error {
+		return c.RenderTemplate("register.twig", nil)
+	})
+
+	a.POST("/register", func(c leopard.ContextInterface) {
+		var form struct {
+			Email    string `json:"email" form:"email" validate:"required,email"`
+			Password string `json:"password" form:"password" validate:"required"`
+		}
+
+		if err := c.BindAndValidate(&form); err != nil {
+			c.WithInput(c.ReadForm())
+			c.WithErrors(err)
+		} else {
+			c.Flash("success", "Welcome!")
+		}
+
+		c.Redirect("/register")
+	})
+
+	return a
+}
+
+func TestFlashes(t *testing.T) {
+	for name, config := range map[string]leopard.FlashConfig{
+		"session": {},
+		"cookie":  {Cookie: true, Secret: "secret"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client := flashApp(t, config).Test()
+
+			client.GET("/register").Expect(t).
+				Status(200).
+				BodyEquals(`<input name="email" value=""><input name="password" value="">`)
+
+			client.POST("/register").WithForm(url.Values{"email": {"not <an> email"}, "password": {"secret"}}).Expect(t).
+				Status(302).
+				Header("Location", "/register")
+
+			client.GET("/register").Expect(t).
+				Status(200).
+				BodyEquals(`<input name="email" value="not &lt;an&gt; email"><input name="password" value="">` +
+					`<span>email must be a valid email address</span>`)
+
+			// The data is only kept for one request.
+			client.GET("/register").Expect(t).
+				BodyEquals(`<input name="email" value=""><input name="password" value="">`)
+
+			client.POST("/register").WithForm(url.Values{"email": {"bob@example.com"}, "password": {"secret"}}).Expect(t).Status(302)
+
+			client.GET("/register").Expect(t).
+				BodyEquals(`<p>Welcome!</p><input name="email" value=""><input name="password" value="">`)
+		})
+	}
+}
+
+func TestFlashWithoutMiddleware(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/", func(c leopard.ContextInterface) error {
+		_, err := c.WriteStringF("%q %q", c.Flashes("success"), c.Old("email"))
+
+		return err
+	})
+
+	a.POST("/", func(c leopard.ContextInterface) {
+		c.Flash("success", "lost")
+	})
+
+	client := a.Test()
+
+	client.GET("/").Expect(t).Status(200).BodyEquals(`[] ""`)
+	client.POST("/").Expect(t).Status(500)
+}
+
+func TestFlashCookieTooLarge(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger := defaultlogger.New()
+	logger.SetOutput(output)
+
+	a := leopardtest.New(t, leopard.WithLogger(logger))
+
+	a.Use(leopard.Flashes(leopard.FlashConfig{Cookie: true, Secret: "secret"}))
+
+	a.POST("/", func(c leopard.ContextInterface) {
+		c.WithInput(c.ReadForm())
+		c.Redirect("/")
+	})
+
+	client := a.Test()
+	client.POST("/").WithForm(url.Values{"bio": {strings.Repeat("a", 4096)}}).Expect(t).Status(302)
+
+	if len(client.Cookies("/")) != 0 {
+		t.Error("expected no flash cookie for data that does not fit")
+	}
+
+	if !strings.Contains(output.String(), leopard.ErrFlashTooLarge.Error()) {
+		t.Errorf("expected the dropped data to be logged, got %q", output.String())
+	}
+}
+
+func TestFlashWithoutErrors(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.Use(leopard.Flashes(leopard.FlashConfig{Cookie: true, Secret: "secret"}))
+
+	a.POST("/", func(c leopard.ContextInterface) error {
+		c.WithErrors(nil)
+
+		_, err := c.WriteStringF("%q", c.FieldErrors(""))
+
+		return err
+	})
+
+	a.Test().POST("/").Expect(t).Status(200).BodyEquals("[]")
+}
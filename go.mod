@@ -3,18 +3,23 @@ module github.com/volix-dev/leopard
 go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.0.6
+	github.com/aws/aws-sdk-go v1.43.41
+	github.com/go-redis/redis/v8 v8.11.5
 	github.com/gorilla/mux v1.8.0
 	github.com/joho/godotenv v1.4.0
+	github.com/klauspost/compress v1.16.7
 	github.com/sirupsen/logrus v1.8.1
 	github.com/tyler-sommer/stick v1.0.4
+	golang.org/x/crypto v0.10.0
+	golang.org/x/net v0.11.0
 )
 
 require (
-	github.com/aws/aws-sdk-go v1.43.41 // indirect
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
-	github.com/go-redis/redis/v8 v8.11.5 // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/shopspring/decimal v1.3.1 // indirect
-	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad // indirect
+	golang.org/x/sys v0.9.0 // indirect
+	golang.org/x/text v0.10.0 // indirect
 )
@@ -1,10 +1,71 @@
 package leopard
 
 import (
+	"github.com/gorilla/mux"
 	"net/http"
+	"strings"
 )
 
-func (a LeopardApp) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+// routeMethods are the methods that are checked to find the methods a path allows.
+var routeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+func (a *LeopardApp) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	var match mux.RouteMatch
+
+	// OPTIONS requests for paths without an OPTIONS route are answered with the allowed methods,
+	// the global middleware runs first so CORS middleware can answer preflight requests.
+	if request.Method == http.MethodOptions && a.router.Match(request, &match) && match.MatchErr == mux.ErrMethodMismatch {
+		allowed := a.allowedMethods(request)
+
+		a.serveChain(writer, request, joinHandlers(a.middleware, []HandlerFunc{func(c ContextInterface) error {
+			c.SetHeader("Allow", strings.Join(allowed, ", "))
+			c.Status(http.StatusNoContent)
+
+			return nil
+		}}))
+
+		return
+	}
 
 	a.router.ServeHTTP(writer, request)
 }
+
+// allowedMethods gets the methods that have a route for the path of the request, OPTIONS is always allowed.
+func (a *LeopardApp) allowedMethods(request *http.Request) []string {
+	var allowed []string
+
+	for _, method := range routeMethods {
+		var match mux.RouteMatch
+
+		candidate := request.Clone(request.Context())
+		candidate.Method = method
+
+		if method == http.MethodOptions || (a.router.Match(candidate, &match) && match.MatchErr == nil) {
+			allowed = append(allowed, method)
+		}
+	}
+
+	return allowed
+}
+
+// headWriter discards the body of responses to HEAD requests, the headers are still written.
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (w headWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+// Unwrap returns the wrapped response writer, it is used by http.ResponseController.
+func (w headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
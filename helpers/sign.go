@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// Sign appends an HMAC-SHA256 signature of the value, so it can be sent to the client and trusted when it comes back.
+// The value itself is readable by the client, it is only protected against changes.
+func Sign(value string, secret []byte) string {
+	return value + "." + signature(value, secret)
+}
+
+// Unsign checks the signature of a value created by Sign and returns the value without it.
+func Unsign(signed string, secret []byte) (string, bool) {
+	i := strings.LastIndexByte(signed, '.')
+
+	if i < 0 {
+		return "", false
+	}
+
+	value := signed[:i]
+
+	if !hmac.Equal([]byte(signed[i+1:]), []byte(signature(value, secret))) {
+		return "", false
+	}
+
+	return value, true
+}
+
+func signature(value string, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,35 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+)
+
+// crockford is the base32 alphabet used by ULIDs, it leaves out I, L, O and U.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID generates a ULID: a 26 character, lexicographically sortable identifier
+// made of a millisecond timestamp and 80 random bits.
+func NewULID() string {
+	var data [16]byte
+
+	binary.BigEndian.PutUint64(data[:8], uint64(time.Now().UnixMilli())<<16)
+
+	if _, err := rand.Read(data[6:]); err != nil {
+		panic(err)
+	}
+
+	// 128 bits are encoded as 26 characters of 5 bits, the first character only uses 3 bits.
+	var id [26]byte
+	hi := binary.BigEndian.Uint64(data[:8])
+	lo := binary.BigEndian.Uint64(data[8:])
+
+	for i := 25; i >= 0; i-- {
+		id[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(id[:])
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+// UUID is a 128 bit universally unique identifier.
+type UUID [16]byte
+
+// ParseUUID parses a UUID in the canonical 8-4-4-4-12 hexadecimal form.
+func ParseUUID(s string) (UUID, error) {
+	var uuid UUID
+
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return uuid, errors.New("invalid UUID format")
+	}
+
+	digits := s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:36]
+
+	if _, err := hex.Decode(uuid[:], []byte(digits)); err != nil {
+		return uuid, errors.New("invalid UUID format")
+	}
+
+	return uuid, nil
+}
+
+// String formats the UUID in the canonical 8-4-4-4-12 hexadecimal form.
+func (u UUID) String() string {
+	s := hex.EncodeToString(u[:])
+
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
@@ -1,15 +1,101 @@
 package leopard
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
+
+// ServerConfig configures the http server.
+// Zero values are read from the environment, set a timeout to NoTimeout to turn it off,
+// for example the WriteTimeout for long-lived streaming responses.
+type ServerConfig struct {
+	// ReadTimeout is the maximum duration for reading the entire request, defaults to READ_TIMEOUT.
+	ReadTimeout time.Duration
+
+	// ReadHeaderTimeout is the maximum duration for reading the request headers, defaults to READ_HEADER_TIMEOUT.
+	ReadHeaderTimeout time.Duration
+
+	// WriteTimeout is the maximum duration before timing out writes of the response, defaults to WRITE_TIMEOUT.
+	WriteTimeout time.Duration
+
+	// IdleTimeout is the maximum duration to wait for the next request on a keep-alive connection, defaults to IDLE_TIMEOUT.
+	IdleTimeout time.Duration
+
+	// MaxHeaderBytes is the maximum size of the request headers, defaults to MAX_HEADER_BYTES.
+	MaxHeaderBytes int
+
+	// H2C enables HTTP/2 without TLS on Serve, defaults to H2C.
+	// This should only be used for internal traffic, for example behind a load balancer.
+	H2C bool
+}
+
+// NoTimeout turns a timeout of the ServerConfig off, a timeout of 0 in the environment does the same.
+const NoTimeout time.Duration = -1
+
+// fillFromEnv sets all the zero values to the values from the environment.
+func (c *ServerConfig) fillFromEnv() error {
+	var err error
+
+	durations := []struct {
+		target       *time.Duration
+		setting      string
+		defaultValue string
+	}{
+		{&c.ReadTimeout, "READ_TIMEOUT", "30s"},
+		{&c.ReadHeaderTimeout, "READ_HEADER_TIMEOUT", "10s"},
+		{&c.WriteTimeout, "WRITE_TIMEOUT", "60s"},
+		{&c.IdleTimeout, "IDLE_TIMEOUT", "120s"},
+	}
+
+	for _, d := range durations {
+		if *d.target != 0 {
+			continue
+		}
+
+		*d.target, err = envDuration(d.setting, d.defaultValue)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.MaxHeaderBytes == 0 {
+		c.MaxHeaderBytes, err = envInt("MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if !c.H2C {
+		c.H2C, err = envBool("H2C", false)
+	}
+
+	return err
+}
 
 type SimpleServer struct {
 	*http.Server
 }
 
-func NewSimpleServer(addr string) *SimpleServer {
+func NewSimpleServer(addr string, config ServerConfig) *SimpleServer {
 	return &SimpleServer{
 		Server: &http.Server{
-			Addr: addr,
+			Addr:              addr,
+			ReadTimeout:       timeout(config.ReadTimeout),
+			ReadHeaderTimeout: timeout(config.ReadHeaderTimeout),
+			WriteTimeout:      timeout(config.WriteTimeout),
+			IdleTimeout:       timeout(config.IdleTimeout),
+			MaxHeaderBytes:    config.MaxHeaderBytes,
 		},
 	}
 }
+
+// timeout converts NoTimeout to the 0 of http.Server, which means there is no timeout.
+func timeout(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
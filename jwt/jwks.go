@@ -0,0 +1,198 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// jwk is a key of a JSON Web Key Set, as described by RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+	K   string `json:"k,omitempty"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// LoadJWKSFile reads a key set from a JSON Web Key Set file.
+// Keys with private parts (d, or k for secrets) can sign tokens, keys for encryption are skipped.
+// Rotate keys by adding a new key at the top of the file and removing the old one once its tokens expired.
+func LoadJWKSFile(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(data)
+}
+
+// ParseJWKS reads a key set from a JSON Web Key Set.
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var set jwks
+
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwt: invalid key set: %w", err)
+	}
+
+	keys := &KeySet{}
+
+	for _, entry := range set.Keys {
+		if entry.Use == "enc" {
+			continue
+		}
+
+		key, err := entry.key()
+
+		if err != nil {
+			return nil, fmt.Errorf("jwt: invalid key %q: %w", entry.Kid, err)
+		}
+
+		keys.keys = append(keys.keys, key)
+	}
+
+	return keys, nil
+}
+
+// key converts the entry to a Key.
+func (k jwk) key() (*Key, error) {
+	decoder := &decoder{}
+
+	switch k.Kty {
+	case "oct":
+		return NewKey(k.Kid, decoder.bytes(k.K))
+
+	case "RSA":
+		public := &rsa.PublicKey{N: decoder.int(k.N), E: int(decoder.int(k.E).Int64())}
+
+		if decoder.err != nil || k.D == "" {
+			return decoder.newKey(k.Kid, public)
+		}
+
+		private := &rsa.PrivateKey{
+			PublicKey: *public,
+			D:         decoder.int(k.D),
+			Primes:    []*big.Int{decoder.int(k.P), decoder.int(k.Q)},
+		}
+
+		if decoder.err == nil {
+			decoder.err = private.Validate()
+		}
+
+		private.Precompute()
+
+		return decoder.newKey(k.Kid, private)
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+
+		public := &ecdsa.PublicKey{Curve: elliptic.P256(), X: decoder.int(k.X), Y: decoder.int(k.Y)}
+
+		if decoder.err != nil || k.D == "" {
+			return decoder.newKey(k.Kid, public)
+		}
+
+		return decoder.newKey(k.Kid, &ecdsa.PrivateKey{PublicKey: *public, D: decoder.int(k.D)})
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+
+		if k.D != "" {
+			seed := decoder.bytes(k.D)
+
+			if decoder.err == nil && len(seed) != ed25519.SeedSize {
+				return nil, fmt.Errorf("invalid Ed25519 private key")
+			}
+
+			return decoder.newKey(k.Kid, ed25519.NewKeyFromSeed(seed))
+		}
+
+		return decoder.newKey(k.Kid, ed25519.PublicKey(decoder.bytes(k.X)))
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+// decoder decodes base64url values and keeps the first error.
+type decoder struct {
+	err error
+}
+
+func (d *decoder) bytes(value string) []byte {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil && d.err == nil {
+		d.err = err
+	}
+
+	return data
+}
+
+func (d *decoder) int(value string) *big.Int {
+	return new(big.Int).SetBytes(d.bytes(value))
+}
+
+func (d *decoder) newKey(id string, key any) (*Key, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return NewKey(id, key)
+}
+
+// JWKS gets the public keys of the set as a JSON Web Key Set, so other services can verify the tokens.
+// Serve it at /.well-known/jwks.json for example. HS256 secrets are never included.
+func (s *KeySet) JWKS() ([]byte, error) {
+	set := jwks{Keys: []jwk{}}
+	encode := base64.RawURLEncoding.EncodeToString
+
+	for _, key := range s.keys {
+		entry := jwk{Kid: key.ID, Alg: key.Algorithm, Use: "sig"}
+
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			entry.Kty = "RSA"
+			entry.N = encode(public.N.Bytes())
+			entry.E = encode(big.NewInt(int64(public.E)).Bytes())
+
+		case *ecdsa.PublicKey:
+			entry.Kty, entry.Crv = "EC", "P-256"
+			entry.X = encode(public.X.FillBytes(make([]byte, 32)))
+			entry.Y = encode(public.Y.FillBytes(make([]byte, 32)))
+
+		case ed25519.PublicKey:
+			entry.Kty, entry.Crv = "OKP", "Ed25519"
+			entry.X = encode(public)
+
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, entry)
+	}
+
+	return json.MarshalIndent(set, "", "  ")
+}
@@ -0,0 +1,452 @@
+// Package jwt issues and verifies JSON Web Tokens for stateless authentication of api requests.
+//
+// Tokens implements auth.Guard, the verified Token becomes the user of the request:
+//
+//	tokens := jwt.New(jwt.Config{Keys: keys, Issuer: "https://example.com"})
+//	app.Group("/api", routes, tokens.Middleware())
+package jwt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/auth"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Errors returned by Parse, they all match ErrInvalidToken.
+var (
+	ErrInvalidToken    = errors.New("jwt: invalid token")
+	ErrUnknownKey      = fmt.Errorf("%w: unknown key", ErrInvalidToken)
+	ErrSignature       = fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+	ErrExpired         = fmt.Errorf("%w: expired", ErrInvalidToken)
+	ErrNotYetValid     = fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	ErrInvalidIssuer   = fmt.Errorf("%w: invalid issuer", ErrInvalidToken)
+	ErrInvalidAudience = fmt.Errorf("%w: invalid audience", ErrInvalidToken)
+)
+
+// Claims are the claims of a token, registered claims like exp have getters.
+// Numbers in parsed claims are float64, like in all json decoded into interfaces.
+type Claims map[string]any
+
+// Subject gets the sub claim, usually the ID of the user.
+func (c Claims) Subject() string {
+	subject, _ := c["sub"].(string)
+
+	return subject
+}
+
+// Issuer gets the iss claim.
+func (c Claims) Issuer() string {
+	issuer, _ := c["iss"].(string)
+
+	return issuer
+}
+
+// ID gets the jti claim, the unique ID of the token.
+func (c Claims) ID() string {
+	id, _ := c["jti"].(string)
+
+	return id
+}
+
+// Audience gets the aud claim, it can be a single string or a list in the token.
+func (c Claims) Audience() []string {
+	switch audience := c["aud"].(type) {
+	case string:
+		return []string{audience}
+
+	case []string:
+		return audience
+
+	case []any:
+		var list []string
+
+		for _, a := range audience {
+			if s, ok := a.(string); ok {
+				list = append(list, s)
+			}
+		}
+
+		return list
+	}
+
+	return nil
+}
+
+// ExpiresAt gets the exp claim, it is the zero time when the token does not expire.
+func (c Claims) ExpiresAt() time.Time {
+	return c.time("exp")
+}
+
+// NotBefore gets the nbf claim.
+func (c Claims) NotBefore() time.Time {
+	return c.time("nbf")
+}
+
+// IssuedAt gets the iat claim.
+func (c Claims) IssuedAt() time.Time {
+	return c.time("iat")
+}
+
+func (c Claims) time(claim string) time.Time {
+	switch value := c[claim].(type) {
+	case float64:
+		seconds, fraction := math.Modf(value)
+
+		return time.Unix(int64(seconds), int64(fraction*1e9))
+
+	case int64:
+		return time.Unix(value, 0)
+
+	case int:
+		return time.Unix(int64(value), 0)
+	}
+
+	return time.Time{}
+}
+
+// Config configures the issuing and verification of tokens.
+type Config struct {
+	// Keys sign and verify the tokens, see LoadJWKSFile to read them from a file.
+	Keys *KeySet
+
+	// Issuer is the iss claim of issued tokens, verified tokens must have it when it is set.
+	Issuer string
+
+	// Audience is the aud claim of issued tokens, verified tokens must have one of them when it is set.
+	Audience []string
+
+	// TTL is how long issued tokens are valid, defaults to an hour.
+	TTL time.Duration
+
+	// Leeway is the allowed difference between the clocks of servers when checking exp and nbf.
+	Leeway time.Duration
+}
+
+// Tokens issues and verifies tokens.
+type Tokens struct {
+	config Config
+	now    func() time.Time
+}
+
+// New creates Tokens with the config, it panics when the config has no keys.
+func New(config Config) *Tokens {
+	if config.Keys == nil {
+		panic("jwt: the config needs Keys")
+	}
+
+	if config.TTL == 0 {
+		config.TTL = time.Hour
+	}
+
+	return &Tokens{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// Issue signs a token with the claims. The iss, aud, iat, exp and jti claims are set
+// from the config when they are not in the claims, the claims are not changed.
+func (t *Tokens) Issue(claims Claims) (string, error) {
+	key, err := t.config.Keys.signingKey()
+
+	if err != nil {
+		return "", err
+	}
+
+	now := t.now()
+	payload := Claims{
+		"iat": now.Unix(),
+		"exp": now.Add(t.config.TTL).Unix(),
+		"jti": newID(),
+	}
+
+	if t.config.Issuer != "" {
+		payload["iss"] = t.config.Issuer
+	}
+
+	if len(t.config.Audience) == 1 {
+		payload["aud"] = t.config.Audience[0]
+	} else if len(t.config.Audience) > 1 {
+		payload["aud"] = t.config.Audience
+	}
+
+	for claim, value := range claims {
+		payload[claim] = value
+	}
+
+	headerData, err := json.Marshal(header{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+
+	if err != nil {
+		return "", err
+	}
+
+	payloadData, err := json.Marshal(payload)
+
+	if err != nil {
+		return "", err
+	}
+
+	input := encode(headerData) + "." + encode(payloadData)
+	signature, err := key.sign([]byte(input))
+
+	if err != nil {
+		return "", err
+	}
+
+	return input + "." + encode(signature), nil
+}
+
+// Parse verifies the signature and the claims of the token and returns its claims.
+// The key is found by the kid in the header, the algorithm of the token must be the algorithm of the key.
+func (t *Tokens) Parse(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var h header
+
+	if err := decodeJSON(parts[0], &h); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	keys := t.config.Keys.find(h.KeyID, h.Algorithm)
+
+	if len(keys) == 0 {
+		return nil, ErrUnknownKey
+	}
+
+	input := []byte(parts[0] + "." + parts[1])
+	valid := false
+
+	for _, key := range keys {
+		if key.verify(input, signature) {
+			valid = true
+
+			break
+		}
+	}
+
+	if !valid {
+		return nil, ErrSignature
+	}
+
+	var claims Claims
+
+	if err := decodeJSON(parts[1], &claims); err != nil || claims == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, t.validate(claims)
+}
+
+// validate checks the registered claims.
+func (t *Tokens) validate(claims Claims) error {
+	now := t.now()
+
+	if exp := claims.ExpiresAt(); !exp.IsZero() && !now.Before(exp.Add(t.config.Leeway)) {
+		return ErrExpired
+	}
+
+	if nbf := claims.NotBefore(); !nbf.IsZero() && now.Add(t.config.Leeway).Before(nbf) {
+		return ErrNotYetValid
+	}
+
+	if t.config.Issuer != "" && claims.Issuer() != t.config.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if len(t.config.Audience) == 0 {
+		return nil
+	}
+
+	for _, audience := range claims.Audience() {
+		for _, expected := range t.config.Audience {
+			if audience == expected {
+				return nil
+			}
+		}
+	}
+
+	return ErrInvalidAudience
+}
+
+// Token is a verified token, it is the user of requests authenticated with a token.
+type Token struct {
+	// Raw is the token as it was sent.
+	Raw string
+
+	// Claims are the claims of the token.
+	Claims Claims
+}
+
+// AuthID gets the subject of the token.
+func (t *Token) AuthID() string {
+	return t.Claims.Subject()
+}
+
+// FromContext gets the token of the request, it is nil when the request was not authenticated with a token.
+func FromContext(c leopard.ContextInterface) *Token {
+	token, _ := c.User().(*Token)
+
+	return token
+}
+
+// Authenticate verifies the bearer token of the request, it makes Tokens an auth.Guard.
+// Revoked and invalid tokens are ignored.
+func (t *Tokens) Authenticate(c leopard.ContextInterface) (leopard.User, error) {
+	raw := auth.BearerToken(c)
+
+	if raw == "" {
+		return nil, nil
+	}
+
+	claims, err := t.Parse(raw)
+
+	if err != nil {
+		return nil, nil
+	}
+
+	revoked, err := t.Revoked(c.App().Cache, claims)
+
+	if err != nil || revoked {
+		return nil, err
+	}
+
+	return &Token{Raw: raw, Claims: claims}, nil
+}
+
+// Challenge tells the client to send a valid token.
+func (t *Tokens) Challenge(c leopard.ContextInterface) {
+	header := `Bearer error="invalid_token"`
+
+	if auth.BearerToken(c) == "" {
+		header = "Bearer"
+	}
+
+	c.Response().Header().Set("WWW-Authenticate", header)
+}
+
+// Middleware rejects requests without a valid bearer token with auth.ErrUnauthenticated.
+// The token is available with FromContext, and as user of the request.
+// Use the Tokens as guard of auth.Authenticate or auth.Required to also accept other credentials.
+func (t *Tokens) Middleware() leopard.HandlerFunc {
+	return func(c leopard.ContextInterface) error {
+		user, err := t.Authenticate(c)
+
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			t.Challenge(c)
+
+			return auth.ErrUnauthenticated
+		}
+
+		c.SetUser(user)
+
+		return c.Next()
+	}
+}
+
+// revokedKey is the cache key of a revoked token.
+func revokedKey(id string) string {
+	return "jwt:revoked:" + id
+}
+
+// Revoke adds the token to the revocation list in the cache, it is kept until the token expires.
+// Tokens without a jti claim can not be revoked, tokens issued by Issue always have one.
+func (t *Tokens) Revoke(cache *leopard.Caching, claims Claims) error {
+	id := claims.ID()
+
+	if id == "" {
+		return errors.New("jwt: the token has no jti claim")
+	}
+
+	ttl := 0
+
+	if exp := claims.ExpiresAt(); !exp.IsZero() {
+		ttl = int(math.Ceil(exp.Add(t.config.Leeway).Sub(t.now()).Seconds()))
+
+		if ttl <= 0 {
+			return nil
+		}
+	}
+
+	return cache.SetTTL(revokedKey(id), true, ttl)
+}
+
+// Revoked checks if the token is on the revocation list in the cache.
+func (t *Tokens) Revoked(cache *leopard.Caching, claims Claims) (bool, error) {
+	if claims.ID() == "" {
+		return false, nil
+	}
+
+	var revoked bool
+
+	return cache.Get(revokedKey(claims.ID()), &revoked)
+}
+
+// Handler serves the public keys as a JSON Web Key Set, for example at /.well-known/jwks.json.
+func (t *Tokens) Handler() leopard.HandlerFunc {
+	return func(c leopard.ContextInterface) error {
+		data, err := t.config.Keys.JWKS()
+
+		if err != nil {
+			return err
+		}
+
+		c.SetHeader("Content-Type", "application/json")
+		c.Status(http.StatusOK)
+		_, err = c.Write(data)
+
+		return err
+	}
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeJSON(part string, target any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, target)
+}
+
+// newID generates a random token ID.
+func newID() string {
+	data := make([]byte, 16)
+
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+
+	return encode(data)
+}
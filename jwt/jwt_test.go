@@ -0,0 +1,265 @@
+package jwt_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/jwt"
+	"github.com/volix-dev/leopard/leopardtest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newKey(t *testing.T, id string, key any) *jwt.Key {
+	k, err := jwt.NewKey(id, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k
+}
+
+func TestAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	keys := map[string]any{
+		jwt.HS256: []byte("a secret of at least thirty-two bytes"),
+		jwt.RS256: rsaKey,
+		jwt.ES256: ecKey,
+		jwt.EdDSA: edKey,
+	}
+
+	for algorithm, key := range keys {
+		t.Run(algorithm, func(t *testing.T) {
+			tokens := jwt.New(jwt.Config{Keys: jwt.NewKeySet(newKey(t, "key", key))})
+			token, err := tokens.Issue(jwt.Claims{"sub": "42", "role": "admin"})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := tokens.Parse(token)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if claims.Subject() != "42" || claims["role"] != "admin" || claims.ID() == "" {
+				t.Errorf("unexpected claims %v", claims)
+			}
+
+			if exp := claims.ExpiresAt(); exp.Before(time.Now().Add(59*time.Minute)) || exp.After(time.Now().Add(time.Hour)) {
+				t.Errorf("expected the token to expire in an hour, got %v", exp)
+			}
+
+			parts := strings.Split(token, ".")
+			forged := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1"}`)) + "." + parts[2]
+
+			if _, err := tokens.Parse(forged); !errors.Is(err, jwt.ErrSignature) {
+				t.Errorf("expected ErrSignature for a changed token, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClaimValidation(t *testing.T) {
+	key := newKey(t, "key", []byte("a secret of at least thirty-two bytes"))
+	issuer := jwt.New(jwt.Config{Keys: jwt.NewKeySet(key), Issuer: "https://example.com", Audience: []string{"api"}})
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		claims   jwt.Claims
+		config   jwt.Config
+		expected error
+	}{
+		{"valid", jwt.Claims{}, jwt.Config{Issuer: "https://example.com", Audience: []string{"web", "api"}}, nil},
+		{"expired", jwt.Claims{"exp": now.Add(-time.Minute).Unix()}, jwt.Config{}, jwt.ErrExpired},
+		{"leeway", jwt.Claims{"exp": now.Add(-time.Minute).Unix()}, jwt.Config{Leeway: 2 * time.Minute}, nil},
+		{"not yet valid", jwt.Claims{"nbf": now.Add(time.Minute).Unix()}, jwt.Config{}, jwt.ErrNotYetValid},
+		{"issuer", jwt.Claims{}, jwt.Config{Issuer: "https://other.com"}, jwt.ErrInvalidIssuer},
+		{"audience", jwt.Claims{}, jwt.Config{Audience: []string{"web"}}, jwt.ErrInvalidAudience},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token, err := issuer.Issue(test.claims)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			test.config.Keys = jwt.NewKeySet(key)
+			_, err = jwt.New(test.config).Parse(token)
+
+			if !errors.Is(err, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, err)
+			}
+
+			if test.expected != nil && !errors.Is(err, jwt.ErrInvalidToken) {
+				t.Errorf("expected the error to match ErrInvalidToken")
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	_, oldPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	_, newPrivate, _ := ed25519.GenerateKey(rand.Reader)
+	oldKey, nextKey := newKey(t, "2023", oldPrivate), newKey(t, "2024", newPrivate)
+
+	oldToken, _ := jwt.New(jwt.Config{Keys: jwt.NewKeySet(oldKey)}).Issue(jwt.Claims{"sub": "1"})
+
+	rotated := jwt.New(jwt.Config{Keys: jwt.NewKeySet(nextKey, oldKey)})
+	newToken, _ := rotated.Issue(jwt.Claims{"sub": "1"})
+
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := rotated.Parse(token); err != nil {
+			t.Errorf("expected the token to be valid after the rotation, got %v", err)
+		}
+	}
+
+	if _, err := jwt.New(jwt.Config{Keys: jwt.NewKeySet(oldKey)}).Parse(newToken); !errors.Is(err, jwt.ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey for the kid of the new key, got %v", err)
+	}
+
+	// Only the public keys are published, they verify tokens but can not sign them.
+	published, err := jwt.NewKeySet(nextKey, oldKey).JWKS()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public, err := jwt.ParseJWKS(published)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := jwt.New(jwt.Config{Keys: public})
+
+	if _, err := verifier.Parse(oldToken); err != nil {
+		t.Errorf("expected the published keys to verify the token, got %v", err)
+	}
+
+	if _, err := verifier.Issue(jwt.Claims{}); err == nil {
+		t.Error("expected public keys not to sign tokens")
+	}
+}
+
+func TestLoadJWKSFile(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, _ = rand.Read(seed)
+	encode := base64.RawURLEncoding.EncodeToString
+	public := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	content := `{"keys": [
+		{"kty": "OKP", "crv": "Ed25519", "kid": "signing", "x": "` + encode(public) + `", "d": "` + encode(seed) + `"},
+		{"kty": "oct", "kid": "legacy", "k": "` + encode([]byte("a secret of at least thirty-two bytes")) + `"},
+		{"kty": "RSA", "kid": "encryption", "use": "enc", "n": "AQAB", "e": "AQAB"}
+	]}`
+
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := jwt.LoadJWKSFile(file)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys.Keys()) != 2 || keys.Keys()[0].Algorithm != jwt.EdDSA || keys.Keys()[1].Algorithm != jwt.HS256 {
+		t.Fatalf("unexpected keys %v", keys.Keys())
+	}
+
+	tokens := jwt.New(jwt.Config{Keys: keys})
+	token, err := tokens.Issue(jwt.Claims{"sub": "1"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := jwt.New(jwt.Config{Keys: jwt.NewKeySet(newKey(t, "signing", public))}).Parse(token); err != nil {
+		t.Errorf("expected the token to be signed by the private key of the file, got %v", err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tokens := jwt.New(jwt.Config{Keys: jwt.NewKeySet(newKey(t, "key", []byte("a secret of at least thirty-two bytes")))})
+	a := leopardtest.New(t)
+
+	a.Group("/api", func(group leopard.RouteGroup) {
+		group.GET("/me", func(c leopard.ContextInterface) error {
+			_, err := c.WriteString(jwt.FromContext(c).Claims.Subject() + " " + c.User().AuthID())
+
+			return err
+		})
+
+		group.POST("/logout", func(c leopard.ContextInterface) error {
+			return tokens.Revoke(c.App().Cache, jwt.FromContext(c).Claims)
+		})
+	}, tokens.Middleware())
+
+	a.GET("/.well-known/jwks.json", tokens.Handler())
+
+	token, err := tokens.Issue(jwt.Claims{"sub": "42"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := a.Test()
+
+	client.GET("/api/me").Expect(t).Status(401).Header("WWW-Authenticate", "Bearer")
+	client.GET("/api/me").WithHeader("Authorization", "Bearer invalid").Expect(t).
+		Status(401).
+		Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+
+	client.GET("/api/me").WithHeader("Authorization", "Bearer "+token).Expect(t).Status(200).BodyEquals("42 42")
+	client.POST("/api/logout").WithHeader("Authorization", "Bearer "+token).Expect(t).Status(200)
+	client.GET("/api/me").WithHeader("Authorization", "Bearer "+token).Expect(t).Status(401)
+
+	// HS256 secrets are never published.
+	client.GET("/.well-known/jwks.json").Expect(t).Status(200).JSONPath("keys", []any{})
+}
+
+func TestInvalidKeys(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+
+	for name, key := range map[string]any{
+		"short public key":  public[:16],
+		"short private key": private[:32],
+		"short secret":      []byte("secret"),
+	} {
+		if _, err := jwt.NewKey("key", key); err == nil {
+			t.Errorf("expected an error for a %s", name)
+		}
+	}
+
+	short := base64.RawURLEncoding.EncodeToString(public[:16])
+
+	if _, err := jwt.ParseJWKS([]byte(`{"keys": [{"kty": "OKP", "crv": "Ed25519", "kid": "short", "x": "` + short + `"}]}`)); err == nil {
+		t.Error("expected an error for a short Ed25519 key in the JWKS")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected New to panic without keys")
+		}
+	}()
+
+	jwt.New(jwt.Config{})
+}
@@ -0,0 +1,203 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// The supported signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+	EdDSA = "EdDSA"
+)
+
+// Key signs and verifies tokens with one algorithm.
+// Keys that only have a public key, like the keys of another service, can only verify tokens.
+type Key struct {
+	// ID is sent as kid in the header of the tokens, so the key can be found again after it was rotated.
+	ID string
+
+	// Algorithm is the signing algorithm, it follows from the type of the key.
+	Algorithm string
+
+	private any
+	public  any
+}
+
+// NewKey creates a key with the ID. The algorithm follows from the type of the key:
+//   - []byte is a secret for HS256
+//   - *rsa.PrivateKey and *rsa.PublicKey are for RS256
+//   - *ecdsa.PrivateKey and *ecdsa.PublicKey on the P-256 curve are for ES256
+//   - ed25519.PrivateKey and ed25519.PublicKey are for EdDSA
+func NewKey(id string, key any) (*Key, error) {
+	k := &Key{ID: id}
+
+	switch key := key.(type) {
+	case []byte:
+		if len(key) < 32 {
+			return nil, errors.New("jwt: HS256 secrets need at least 32 bytes")
+		}
+
+		k.Algorithm, k.private, k.public = HS256, key, key
+
+	case *rsa.PrivateKey:
+		k.Algorithm, k.private, k.public = RS256, key, &key.PublicKey
+
+	case *rsa.PublicKey:
+		k.Algorithm, k.public = RS256, key
+
+	case *ecdsa.PrivateKey:
+		k.Algorithm, k.private, k.public = ES256, key, &key.PublicKey
+
+	case *ecdsa.PublicKey:
+		k.Algorithm, k.public = ES256, key
+
+	case ed25519.PrivateKey:
+		if len(key) != ed25519.PrivateKeySize {
+			return nil, errors.New("jwt: invalid Ed25519 private key size")
+		}
+
+		k.Algorithm, k.private, k.public = EdDSA, key, key.Public()
+
+	case ed25519.PublicKey:
+		if len(key) != ed25519.PublicKeySize {
+			return nil, errors.New("jwt: invalid Ed25519 public key size")
+		}
+
+		k.Algorithm, k.public = EdDSA, key
+
+	default:
+		return nil, fmt.Errorf("jwt: unsupported key type %T", key)
+	}
+
+	if public, ok := k.public.(*ecdsa.PublicKey); ok && public.Curve != elliptic.P256() {
+		return nil, errors.New("jwt: ES256 keys must use the P-256 curve")
+	}
+
+	return k, nil
+}
+
+// CanSign checks if the key has a private key or secret.
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// sign signs the input of a token.
+func (k *Key) sign(input []byte) ([]byte, error) {
+	switch private := k.private.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, private)
+		mac.Write(input)
+
+		return mac.Sum(nil), nil
+
+	case *rsa.PrivateKey:
+		hash := sha256.Sum256(input)
+
+		return rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, hash[:])
+
+	case *ecdsa.PrivateKey:
+		hash := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, private, hash[:])
+
+		if err != nil {
+			return nil, err
+		}
+
+		// The signature is r and s as fixed size big-endian numbers, not the ASN.1 form of Go.
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+
+		return signature, nil
+
+	case ed25519.PrivateKey:
+		return ed25519.Sign(private, input), nil
+	}
+
+	return nil, errors.New("jwt: the key can not sign tokens")
+}
+
+// verify checks the signature of the input of a token.
+func (k *Key) verify(input []byte, signature []byte) bool {
+	switch public := k.public.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, public)
+		mac.Write(input)
+
+		return hmac.Equal(signature, mac.Sum(nil))
+
+	case *rsa.PublicKey:
+		hash := sha256.Sum256(input)
+
+		return rsa.VerifyPKCS1v15(public, crypto.SHA256, hash[:], signature) == nil
+
+	case *ecdsa.PublicKey:
+		if len(signature) != 64 {
+			return false
+		}
+
+		hash := sha256.Sum256(input)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		return ecdsa.Verify(public, hash[:], r, s)
+
+	case ed25519.PublicKey:
+		return ed25519.Verify(public, input, signature)
+	}
+
+	return false
+}
+
+// KeySet holds the keys of an issuer. The first key that can sign signs new tokens,
+// the other keys verify the tokens that were signed before the keys were rotated.
+type KeySet struct {
+	keys []*Key
+}
+
+// NewKeySet creates a key set, put the newest key first.
+func NewKeySet(keys ...*Key) *KeySet {
+	return &KeySet{keys: keys}
+}
+
+// Keys gets the keys of the set.
+func (s *KeySet) Keys() []*Key {
+	return s.keys
+}
+
+// signingKey gets the key that signs new tokens.
+func (s *KeySet) signingKey() (*Key, error) {
+	for _, key := range s.keys {
+		if key.CanSign() {
+			return key, nil
+		}
+	}
+
+	return nil, errors.New("jwt: the key set has no key that can sign tokens")
+}
+
+// find gets the key for the header of a token, the algorithm of the key must match the algorithm of the token.
+// Tokens without a kid are checked against all keys with the algorithm.
+func (s *KeySet) find(id string, algorithm string) []*Key {
+	var found []*Key
+
+	for _, key := range s.keys {
+		if key.Algorithm == algorithm && (id == "" || key.ID == id) {
+			found = append(found, key)
+		}
+	}
+
+	return found
+}
@@ -1,122 +1,195 @@
 package leopard
 
 import (
+	"context"
 	"errors"
 	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
-	cacheDrivers "github.com/volix-dev/leopard/caching/drivers"
 	"github.com/volix-dev/leopard/files"
 	"github.com/volix-dev/leopard/templating"
 	"github.com/volix-dev/leopard/templating/drivers"
+	"github.com/volix-dev/leopard/templating/drivers/twigDriver"
+	"github.com/volix-dev/leopard/validation"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"io"
 	"net/http"
-	"strconv"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 )
 
 type LeopardApp struct {
 	*Options
-	router   *mux.Router
-	server   *SimpleServer
-	settings map[string]SettingValue
+	router         *mux.Router
+	server         *SimpleServer
+	redirectServer *SimpleServer
+	settings       map[string]SettingValue
 
 	TemplateDriver drivers.TemplatingDriver
 	Cache          *Caching
 	FileDriver     files.Driver
 
 	ContextCreator func(r *http.Request, w http.ResponseWriter, a *LeopardApp) ContextInterface
+
+	// Validator validates the structs passed to Context.Validate and Context.BindAndValidate.
+	// It defaults to validation.Default, set it to use other rules or localized messages.
+	Validator *validation.Validator
+
+	// ErrorHandler writes the response for errors passed to Context.Error and panics in handlers.
+	ErrorHandler ErrorHandlerFunc
+
+	errorMappings    []errorMapping
+	middleware       []HandlerFunc
+	notFound         []fallback
+	methodNotAllowed []fallback
+
+	errorPages     *twigDriver.TwigDriver
+	errorPagesOnce sync.Once
+	errorPagesErr  error
+
+	shutdownHooks []ShutdownHook
+	shutdownLock  sync.Mutex
+	closeOnce     sync.Once
+	closeErr      error
 }
 
-func New() (*LeopardApp, error) {
+// New creates a new LeopardApp.
+// Everything that is not configured with an Option is read from the environment,
+// after loading the .env file.
+func New(opts ...Option) (*LeopardApp, error) {
+	options, err := newOptions(opts)
+
+	if err != nil {
+		return nil, err
+	}
+
 	app := &LeopardApp{
+		Options:        options,
 		router:         mux.NewRouter(),
-		TemplateDriver: templating.TwigCreator(),
+		TemplateDriver: options.templateDriver,
+		ErrorHandler:   DefaultErrorHandler,
+		Validator:      validation.Default,
 		ContextCreator: func(r *http.Request, w http.ResponseWriter, a *LeopardApp) ContextInterface {
-			return &Context{
-				request:        r,
-				responseWriter: w,
-				a:              a,
-				vars:           mux.Vars(r),
-			}
+			return NewContext(w, r, a)
 		},
 	}
 
-	err := godotenv.Load()
+	app.router.NotFoundHandler = http.HandlerFunc(app.serveNotFound)
+	app.router.MethodNotAllowedHandler = http.HandlerFunc(app.serveMethodNotAllowed)
+
+	if app.TemplateDriver == nil {
+		app.TemplateDriver = templating.TwigCreator()
+	}
+
+	err = app.TemplateDriver.Load(options.TemplatePath, app.router)
 
-	err = app.TemplateDriver.Load(
-		EnvSettingD("TEMPLATE_PATH", "./templates").GetValue().(string),
-		app.router,
-	)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: move this to caching.go
-	driverName := EnvSettingD("CACHE_DRIVER", "memory").GetValue().(string)
-
-	switch driverName {
-	case "redis":
-		port, err := strconv.Atoi(EnvSettingD("REDIS_PORT", "6379").GetValue().(string))
-		db, err := strconv.Atoi(EnvSettingD("REDIS_DB", "0").GetValue().(string))
+	if options.cacheDriver != nil {
+		app.Cache = &Caching{Driver: options.cacheDriver}
+	} else {
+		app.Cache, err = app.cacheFromEnv()
 
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		cache, err := newCaching(driverName, cacheDrivers.RedisSettings{
-			Host:     EnvSettingD("REDIS_HOST", "localhost").GetValue().(string),
-			Port:     port,
-			Password: EnvSettingD("REDIS_PASSWORD", "").GetValue().(string),
-			Database: db,
-		})
+	err = app.Cache.open()
 
-		if err != nil {
-			return nil, err
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		app.Cache = cache
+	app.OnShutdown(func(ctx context.Context) error {
+		return app.Cache.close()
+	})
 
-		break
+	app.FileDriver = options.fileDriver
 
-	case "memory":
-		cache, err := newCaching(driverName, nil)
+	if app.FileDriver == nil {
+		app.FileDriver, err = app.getFileDriver()
 
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		app.Cache = cache
+	app.OnShutdown(func(ctx context.Context) error {
+		if closer, ok := app.FileDriver.(io.Closer); ok {
+			return closer.Close()
+		}
+
+		return nil
+	})
 
-		break
+	return app, nil
+}
+
+// Serve starts the server
+// It will listen on the port specified in the options.
+// Should only be called once.
+//
+// When an interrupt or terminate signal is received the server stops accepting new connections,
+// waits for in-flight requests to finish (up to the ShutdownTimeout) and closes all resources.
+//
+// When H2C is enabled in the server config, HTTP/2 is also served without TLS.
+func (a *LeopardApp) Serve() error {
+	a.server = NewSimpleServer(a.ListenAddr, a.Server)
+	a.server.Handler = a
 
-	default:
-		return nil, errors.New("cache driver not found")
+	if a.Server.H2C {
+		a.server.Handler = h2c.NewHandler(a, &http2.Server{
+			IdleTimeout: a.Server.IdleTimeout,
+		})
 	}
 
-	err = app.Cache.open()
+	return a.serve(a.server.ListenAndServe)
+}
 
-	if err != nil {
-		return nil, err
+// serve runs the listen functions until one of them fails or a shutdown signal is received.
+func (a *LeopardApp) serve(listeners ...func() error) error {
+	signals, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, len(listeners))
+
+	for _, listen := range listeners {
+		go func(listen func() error) {
+			serverErr <- listen()
+		}(listen)
 	}
 
-	fileDriver, err := getFileDriver()
+	select {
+	case err := <-serverErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+
+		_ = a.Close()
 
-	if err != nil {
-		return nil, err
+		return err
+
+	case <-signals.Done():
+		stop()
 	}
 
-	app.FileDriver = fileDriver
+	ctx, cancel := context.WithTimeout(context.Background(), a.ShutdownTimeout)
+	defer cancel()
 
-	return app, nil
+	return a.Shutdown(ctx)
 }
 
-// Serve starts the server
-// It will listen on the port specified in the options.
-// Should only be called once.
-func (a *LeopardApp) Serve() error {
-	a.server = NewSimpleServer(EnvSettingD("LISTEN", ":8080").GetValue().(string))
-	a.server.Handler = a.router
-	err := a.server.ListenAndServe()
+// logger gets the logger of the app, or the package Logger when the app has no options.
+func (a *LeopardApp) logger() LoggerInterface {
+	if a == nil || a.Options == nil || a.Options.Logger == nil {
+		return Logger
+	}
 
-	return err
+	return a.Options.Logger
 }
 
 // GetRouter gets the mux router
@@ -124,12 +197,26 @@ func (a *LeopardApp) GetRouter() *mux.Router {
 	return a.router
 }
 
-// Close closes all connections and resources.
+// Close closes all connections and resources immediately, without waiting for in-flight requests.
 // It is not safe to use a leopard app after it has been closed.
 // Close should be called before exiting the program.
 //
-// Close gets called automatically when there is an interrupt signal.
-// If you have any other way of closing the app, you should call this function.
+// Serve shuts down gracefully when there is an interrupt signal.
+// If you have any other way of closing the app, you should call this function or Shutdown.
 func (a *LeopardApp) Close() error {
-	return a.server.Close()
+	var err error
+
+	for _, server := range a.servers() {
+		if closeErr := server.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	closeErr := a.closeResources(context.Background())
+
+	if err == nil {
+		err = closeErr
+	}
+
+	return err
 }
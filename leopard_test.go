@@ -1,35 +1,38 @@
-package leopard
+package leopard_test
 
 import (
 	"errors"
-	"leopard/templating/drivers"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"github.com/volix-dev/leopard/templating/drivers"
 	"testing"
 )
 
-func TestLog(t *testing.T) {
-	a, err := New()
+func TestApp(t *testing.T) {
+	a := leopardtest.New(t)
 
-	if err != nil {
-		panic(err)
-	}
-	a.GET("/kanker/{name}", "test", func(c *Context) {
+	a.GET("/hello/{name}", func(c leopard.ContextInterface) {
 		err := c.RenderTemplate("test.twig", map[string]drivers.Value{
-			"test": "a",
+			"name": c.GetParam("name"),
 		})
 		if err != nil {
 			panic(err)
 		}
-	})
+	}, "test")
 
-	a.GET("/error", "test2", func(c *Context) {
-		c.Error(errors.New("AAAAAAAAA"))
-	})
+	a.GET("/error", func(c leopard.ContextInterface) {
+		_ = c.Error(errors.New("AAAAAAAAA"))
+	}, "test2")
 
-	a.GET("/panic", "test2", func(c *Context) {
+	a.GET("/panic", func(c leopard.ContextInterface) {
 		panic("AAAAAAAAA")
-	})
+	}, "test3")
 
-	a.StaticDir("/assets/", "./public")
+	client := a.Test()
 
-	a.Serve()
+	client.GET("/hello/leopard").Expect(t).Status(200)
+	a.Templates.AssertRenderedWith(t, "test.twig", "name", "leopard")
+
+	client.GET("/error").Expect(t).Status(500).JSONPath("message", "AAAAAAAAA")
+	client.GET("/panic").Expect(t).Status(500).JSONPath("message", "AAAAAAAAA")
 }
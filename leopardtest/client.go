@@ -0,0 +1,262 @@
+package leopardtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// baseURL is the url requests are made to, it is only used to resolve paths and cookies.
+const baseURL = "http://leopard.test"
+
+// Client makes requests directly to an http.Handler and keeps the cookies between requests.
+type Client struct {
+	handler http.Handler
+	jar     http.CookieJar
+	headers http.Header
+}
+
+// NewClient creates a client that sends its requests to the handler.
+func NewClient(handler http.Handler) *Client {
+	jar, _ := cookiejar.New(nil)
+
+	return &Client{
+		handler: handler,
+		jar:     jar,
+		headers: http.Header{},
+	}
+}
+
+// WithHeader sets a header that is sent with every request of the client.
+func (c *Client) WithHeader(key, value string) *Client {
+	c.headers.Set(key, value)
+
+	return c
+}
+
+// GET creates a GET request.
+func (c *Client) GET(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+// POST creates a POST request.
+func (c *Client) POST(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+// PUT creates a PUT request.
+func (c *Client) PUT(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+// PATCH creates a PATCH request.
+func (c *Client) PATCH(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+// DELETE creates a DELETE request.
+func (c *Client) DELETE(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+// HEAD creates a HEAD request.
+func (c *Client) HEAD(path string) *Request {
+	return c.Request(http.MethodHead, path)
+}
+
+// OPTIONS creates an OPTIONS request.
+func (c *Client) OPTIONS(path string) *Request {
+	return c.Request(http.MethodOptions, path)
+}
+
+// Request creates a request with a custom method.
+func (c *Client) Request(method string, path string) *Request {
+	return &Request{
+		client:  c,
+		method:  method,
+		path:    path,
+		headers: c.headers.Clone(),
+		query:   url.Values{},
+	}
+}
+
+// Cookies gets the cookies the client would send to the path.
+func (c *Client) Cookies(path string) []*http.Cookie {
+	u, err := url.Parse(baseURL + path)
+
+	if err != nil {
+		return nil
+	}
+
+	return c.jar.Cookies(u)
+}
+
+// Request is a request that is being built, it is sent with Do or Expect.
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	headers http.Header
+	query   url.Values
+	cookies []*http.Cookie
+	body    io.Reader
+	err     error
+}
+
+// WithHeader sets a request header.
+func (r *Request) WithHeader(key, value string) *Request {
+	r.headers.Set(key, value)
+
+	return r
+}
+
+// WithQuery adds a query parameter.
+func (r *Request) WithQuery(key, value string) *Request {
+	r.query.Add(key, value)
+
+	return r
+}
+
+// WithCookie adds a cookie to the request, on top of the cookies stored in the client.
+func (r *Request) WithCookie(name, value string) *Request {
+	r.cookies = append(r.cookies, &http.Cookie{Name: name, Value: value})
+
+	return r
+}
+
+// WithBody sets the request body.
+func (r *Request) WithBody(body io.Reader) *Request {
+	r.body = body
+
+	return r
+}
+
+// WithJSON marshals the data to json and uses it as request body.
+func (r *Request) WithJSON(data any) *Request {
+	body, err := json.Marshal(data)
+
+	if err != nil {
+		r.err = err
+	}
+
+	r.headers.Set("Content-Type", "application/json")
+
+	return r.WithBody(bytes.NewReader(body))
+}
+
+// WithForm uses the url encoded form as request body.
+func (r *Request) WithForm(form url.Values) *Request {
+	r.headers.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r.WithBody(strings.NewReader(form.Encode()))
+}
+
+// FormFile is a file sent with WithMultipart.
+type FormFile struct {
+	Field    string
+	Filename string
+	Content  []byte
+}
+
+// WithMultipart uses a multipart form with the fields and files as request body.
+func (r *Request) WithMultipart(fields url.Values, files ...FormFile) *Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for key, values := range fields {
+		for _, value := range values {
+			if err := writer.WriteField(key, value); err != nil {
+				r.err = err
+			}
+		}
+	}
+
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.Field, file.Filename)
+
+		if err == nil {
+			_, err = part.Write(file.Content)
+		}
+
+		if err != nil {
+			r.err = err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		r.err = err
+	}
+
+	r.headers.Set("Content-Type", writer.FormDataContentType())
+
+	return r.WithBody(&body)
+}
+
+// Do sends the request to the handler and returns the recorded response.
+func (r *Request) Do() (*http.Response, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	target, err := url.Parse(baseURL + r.path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(r.query) > 0 {
+		query := target.Query()
+
+		for key, values := range r.query {
+			query[key] = append(query[key], values...)
+		}
+
+		target.RawQuery = query.Encode()
+	}
+
+	request := httptest.NewRequest(r.method, target.String(), r.body)
+	request.Header = r.headers
+
+	for _, cookie := range append(r.client.jar.Cookies(target), r.cookies...) {
+		request.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	response.Request = request
+	r.client.jar.SetCookies(target, response.Cookies())
+
+	return response, nil
+}
+
+// Expect sends the request and returns the response to make assertions on.
+func (r *Request) Expect(t testing.TB) *Response {
+	t.Helper()
+
+	response, err := r.Do()
+
+	if err != nil {
+		t.Fatalf("failed to make request %s %s: %v", r.method, r.path, err)
+	}
+
+	body, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		t.Fatalf("failed to read the response body of %s %s: %v", r.method, r.path, err)
+	}
+
+	return &Response{
+		t:        t,
+		Response: response,
+		body:     body,
+	}
+}
@@ -0,0 +1,156 @@
+// Package leopardtest provides helpers to test leopard apps without a network listener.
+//
+// An app created with New uses in memory drivers for the cache and the files,
+// and records every template that gets rendered:
+//
+//	app := leopardtest.New(t)
+//	app.GET("/users/{id}", showUser)
+//
+//	app.Test().GET("/users/1").Expect(t).Status(200).JSONPath("name", "bob")
+package leopardtest
+
+import (
+	"bytes"
+	"errors"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/caching"
+	_ "github.com/volix-dev/leopard/caching/drivers"
+	"github.com/volix-dev/leopard/files/drivers/memFs"
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+// App is a leopard app set up for testing.
+type App struct {
+	*leopard.LeopardApp
+
+	// Templates records the templates rendered by the app.
+	Templates *TemplateRecorder
+}
+
+// New creates a new test app with an in memory cache, an in memory file driver and a template recorder.
+// The provided options are applied after the defaults, so they can replace any of the drivers.
+// The app is closed when the test finishes.
+func New(t testing.TB, opts ...leopard.Option) *App {
+	t.Helper()
+
+	cache, err := caching.New("memory", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := NewTemplateRecorder(nil)
+
+	a, err := leopard.New(append([]leopard.Option{
+		leopard.WithCache(cache),
+		leopard.WithFileDriver(memFs.New()),
+		leopard.WithTemplateDriver(recorder),
+	}, opts...)...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.TemplateDriver != recorder {
+		recorder.next = a.TemplateDriver
+		a.TemplateDriver = recorder
+	}
+
+	t.Cleanup(func() {
+		_ = a.Close()
+	})
+
+	return &App{
+		LeopardApp: a,
+		Templates:  recorder,
+	}
+}
+
+// Test creates a new client to make requests to the app.
+// Cookies set by the app are stored and sent with the next requests of the same client.
+func (a *App) Test() *Client {
+	return NewClient(a.LeopardApp)
+}
+
+// AssertCached asserts that the key is in the cache with the expected value.
+func (a *App) AssertCached(t testing.TB, key string, expected any) {
+	t.Helper()
+
+	if expected == nil {
+		t.Fatalf("can not compare cache key %q with nil, use AssertNotCached instead", key)
+	}
+
+	target := reflect.New(reflect.TypeOf(expected))
+	found, err := a.Cache.Get(key, target.Interface())
+
+	if err != nil {
+		t.Errorf("failed to get cache key %q: %v", key, err)
+		return
+	}
+
+	if !found {
+		t.Errorf("expected cache key %q to be set", key)
+		return
+	}
+
+	if !reflect.DeepEqual(target.Elem().Interface(), expected) {
+		t.Errorf("expected cache key %q to be %#v, got %#v", key, expected, target.Elem().Interface())
+	}
+}
+
+// AssertNotCached asserts that the key is not in the cache.
+func (a *App) AssertNotCached(t testing.TB, key string) {
+	t.Helper()
+
+	var value any
+	found, err := a.Cache.Get(key, &value)
+
+	if err != nil {
+		t.Errorf("failed to get cache key %q: %v", key, err)
+		return
+	}
+
+	if found {
+		t.Errorf("expected cache key %q not to be set, got %#v", key, value)
+	}
+}
+
+// AssertFile asserts that the file exists in the file driver with the expected content.
+func (a *App) AssertFile(t testing.TB, path string, expected []byte) {
+	t.Helper()
+
+	data, err := a.FileDriver.ReadFile(path)
+
+	if err != nil {
+		t.Errorf("expected file %q to exist: %v", path, err)
+		return
+	}
+
+	if !bytes.Equal(data, expected) {
+		t.Errorf("expected file %q to contain %q, got %q", path, expected, data)
+	}
+}
+
+// AssertFileExists asserts that the file exists in the file driver.
+func (a *App) AssertFileExists(t testing.TB, path string) {
+	t.Helper()
+
+	if _, err := a.FileDriver.Stat(path); err != nil {
+		t.Errorf("expected file %q to exist: %v", path, err)
+	}
+}
+
+// AssertNoFile asserts that the file does not exist in the file driver.
+func (a *App) AssertNoFile(t testing.TB, path string) {
+	t.Helper()
+
+	_, err := a.FileDriver.Stat(path)
+
+	if err == nil {
+		t.Errorf("expected file %q not to exist", path)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("failed to stat file %q: %v", path, err)
+	}
+}
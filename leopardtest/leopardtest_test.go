@@ -0,0 +1,108 @@
+package leopardtest
+
+import (
+	"fmt"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/templating/drivers"
+	"io"
+	"testing"
+)
+
+func TestRequestAndAssertions(t *testing.T) {
+	app := New(t)
+
+	app.GET("/users/{id}", func(c leopard.ContextInterface) {
+		c.SetHeader("X-User", c.GetParam("id"))
+		_ = c.Json(map[string]any{
+			"id":    c.GetParam("id"),
+			"name":  "bob",
+			"roles": []string{"admin"},
+			"token": c.GetHeader("X-Token"),
+		})
+	})
+
+	app.Test().GET("/users/1").WithHeader("X-Token", "secret").Expect(t).
+		Status(200).
+		Header("X-User", "1").
+		JSONPath("name", "bob").
+		JSONPath("roles.0", "admin").
+		JSONPath("token", "secret")
+}
+
+func TestCookiesAreKeptBetweenRequests(t *testing.T) {
+	app := New(t)
+
+	app.POST("/login", func(c leopard.ContextInterface) {
+		c.SetCookie("session", "abc", 3600, "/", "", false, true)
+		c.Ok()
+	})
+
+	app.GET("/me", func(c leopard.ContextInterface) {
+		cookie, err := c.GetCookie("session")
+
+		if err != nil {
+			c.Unauthorized()
+			return
+		}
+
+		_, _ = c.WriteString(cookie.Value)
+	})
+
+	client := app.Test()
+
+	client.GET("/me").Expect(t).Status(401)
+	client.POST("/login").Expect(t).Status(200).Cookie("session", "abc")
+	client.GET("/me").Expect(t).Status(200).BodyEquals("abc")
+}
+
+func TestTemplateCacheAndFileAssertions(t *testing.T) {
+	app := New(t)
+
+	app.GET("/profile", func(c leopard.ContextInterface) {
+		_ = c.App().Cache.Set("visits", "1")
+		_ = c.App().FileDriver.WriteFile("logs/visits.txt", []byte("1"))
+		_ = c.RenderTemplate("profile.twig", map[string]drivers.Value{
+			"name": "bob",
+		})
+	})
+
+	app.Test().GET("/profile").Expect(t).Status(200)
+
+	app.Templates.AssertRenderedWith(t, "profile.twig", "name", "bob")
+	app.Templates.AssertNotRendered(t, "home.twig")
+	app.AssertCached(t, "visits", "1")
+	app.AssertNotCached(t, "missing")
+	app.AssertFile(t, "logs/visits.txt", []byte("1"))
+	app.AssertNoFile(t, "logs/missing.txt")
+}
+
+// recordingT records the failures of assertions that are expected to fail.
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertRenderedWithoutData(t *testing.T) {
+	recorder := NewTemplateRecorder(nil)
+	_ = recorder.RenderTemplate("empty.twig", io.Discard, nil)
+
+	failures := &recordingT{TB: t}
+	recorder.AssertRenderedWith(failures, "empty.twig", "name", "bob")
+
+	if len(failures.errors) != 1 {
+		t.Errorf("expected the assertion to fail once, got %v", failures.errors)
+	}
+
+	failures = &recordingT{TB: t}
+	recorder.AssertRenderedWith(failures, "missing.twig", "name", "bob")
+
+	if len(failures.errors) != 1 {
+		t.Errorf("expected the assertion to fail once, got %v", failures.errors)
+	}
+}
@@ -0,0 +1,176 @@
+package leopardtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Response is a recorded response with assertion helpers.
+// Every assertion reports to the test it was created with and returns the response so they can be chained.
+type Response struct {
+	*http.Response
+
+	t    testing.TB
+	body []byte
+}
+
+// Body gets the response body.
+func (r *Response) Body() []byte {
+	return r.body
+}
+
+// BodyString gets the response body as a string.
+func (r *Response) BodyString() string {
+	return string(r.body)
+}
+
+// Status asserts the response status code.
+func (r *Response) Status(status int) *Response {
+	r.t.Helper()
+
+	if r.StatusCode != status {
+		r.t.Errorf("expected status %d, got %d with body %q", status, r.StatusCode, r.body)
+	}
+
+	return r
+}
+
+// Header asserts the value of a response header.
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+
+	if actual := r.Response.Header.Get(key); actual != value {
+		r.t.Errorf("expected header %s to be %q, got %q", key, value, actual)
+	}
+
+	return r
+}
+
+// BodyEquals asserts the full response body.
+func (r *Response) BodyEquals(body string) *Response {
+	r.t.Helper()
+
+	if string(r.body) != body {
+		r.t.Errorf("expected body %q, got %q", body, r.body)
+	}
+
+	return r
+}
+
+// BodyContains asserts that the response body contains the text.
+func (r *Response) BodyContains(text string) *Response {
+	r.t.Helper()
+
+	if !strings.Contains(string(r.body), text) {
+		r.t.Errorf("expected body to contain %q, got %q", text, r.body)
+	}
+
+	return r
+}
+
+// Cookie asserts the value of a cookie set by the response.
+func (r *Response) Cookie(name, value string) *Response {
+	r.t.Helper()
+
+	for _, cookie := range r.Cookies() {
+		if cookie.Name == name {
+			if cookie.Value != value {
+				r.t.Errorf("expected cookie %s to be %q, got %q", name, value, cookie.Value)
+			}
+
+			return r
+		}
+	}
+
+	r.t.Errorf("expected cookie %s to be set", name)
+
+	return r
+}
+
+// JSON unmarshals the response body into the target.
+func (r *Response) JSON(target any) *Response {
+	r.t.Helper()
+
+	if err := json.Unmarshal(r.body, target); err != nil {
+		r.t.Errorf("failed to unmarshal body %q: %v", r.body, err)
+	}
+
+	return r
+}
+
+// JSONPath asserts the value at a dot separated path in the json body.
+// Array elements are selected by their index, for example "users.0.name".
+func (r *Response) JSONPath(path string, expected any) *Response {
+	r.t.Helper()
+
+	var body any
+
+	if err := json.Unmarshal(r.body, &body); err != nil {
+		r.t.Errorf("failed to unmarshal body %q: %v", r.body, err)
+		return r
+	}
+
+	actual, ok := lookup(body, path)
+
+	if !ok {
+		r.t.Errorf("json path %q not found in %s", path, r.body)
+		return r
+	}
+
+	// Round trip the expected value so numbers and structs compare the same way as the body.
+	var normalized any
+	data, err := json.Marshal(expected)
+
+	if err == nil {
+		err = json.Unmarshal(data, &normalized)
+	}
+
+	if err != nil {
+		r.t.Errorf("failed to marshal expected value %#v: %v", expected, err)
+		return r
+	}
+
+	if !reflect.DeepEqual(actual, normalized) {
+		r.t.Errorf("expected json path %q to be %#v, got %#v", path, normalized, actual)
+	}
+
+	return r
+}
+
+// lookup walks the dot separated path in a decoded json value.
+func lookup(value any, path string) (any, bool) {
+	if path == "" {
+		return value, true
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			next, ok := v[key]
+
+			if !ok {
+				return nil, false
+			}
+
+			value = next
+
+		case []any:
+			i, err := strconv.Atoi(key)
+
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+
+			value = v[i]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
@@ -0,0 +1,139 @@
+package leopardtest
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/volix-dev/leopard/templating/drivers"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Render is a template that was rendered.
+type Render struct {
+	Template string
+	Data     map[string]drivers.Value
+}
+
+// TemplateRecorder is a templating driver that records every rendered template.
+// When it wraps another driver the template is also rendered by that driver,
+// otherwise nothing is written to the response.
+type TemplateRecorder struct {
+	next    drivers.TemplatingDriver
+	lock    sync.Mutex
+	renders []Render
+}
+
+// NewTemplateRecorder creates a recorder, next may be nil.
+func NewTemplateRecorder(next drivers.TemplatingDriver) *TemplateRecorder {
+	return &TemplateRecorder{next: next}
+}
+
+func (r *TemplateRecorder) RenderTemplate(template string, writer io.Writer, data map[string]drivers.Value) error {
+	r.lock.Lock()
+	r.renders = append(r.renders, Render{Template: template, Data: data})
+	r.lock.Unlock()
+
+	if r.next != nil {
+		return r.next.RenderTemplate(template, writer, data)
+	}
+
+	return nil
+}
+
+func (r *TemplateRecorder) Load(path string, router *mux.Router) error {
+	if r.next != nil {
+		return r.next.Load(path, router)
+	}
+
+	return nil
+}
+
+// SetAssetResolver passes the resolver to the wrapped driver.
+func (r *TemplateRecorder) SetAssetResolver(resolve func(name string) string) {
+	if resolver, ok := r.next.(drivers.AssetResolver); ok {
+		resolver.SetAssetResolver(resolve)
+	}
+}
+
+// Renders gets all the recorded renders in order.
+func (r *TemplateRecorder) Renders() []Render {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]Render(nil), r.renders...)
+}
+
+// Reset forgets all the recorded renders.
+func (r *TemplateRecorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.renders = nil
+}
+
+// AssertRendered asserts that the template was rendered and returns the last render of it.
+func (r *TemplateRecorder) AssertRendered(t testing.TB, template string) Render {
+	t.Helper()
+
+	renders := r.Renders()
+
+	for i := len(renders) - 1; i >= 0; i-- {
+		if renders[i].Template == template {
+			return renders[i]
+		}
+	}
+
+	t.Errorf("expected template %q to be rendered", template)
+
+	return Render{Template: template}
+}
+
+// AssertRenderedWith asserts that the template was last rendered with the value in its data.
+func (r *TemplateRecorder) AssertRenderedWith(t testing.TB, template string, key string, expected drivers.Value) {
+	t.Helper()
+
+	render := r.AssertRendered(t, template)
+
+	if render.Data == nil {
+		if r.rendered(template) {
+			t.Errorf("expected template %q to be rendered with %q, it was rendered without data", template, key)
+		}
+
+		return
+	}
+
+	actual, ok := render.Data[key]
+
+	if !ok {
+		t.Errorf("expected template %q to be rendered with %q", template, key)
+		return
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected template %q to be rendered with %s = %#v, got %#v", template, key, expected, actual)
+	}
+}
+
+// rendered checks if the template was rendered.
+func (r *TemplateRecorder) rendered(template string) bool {
+	for _, render := range r.Renders() {
+		if render.Template == template {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AssertNotRendered asserts that the template was not rendered.
+func (r *TemplateRecorder) AssertNotRendered(t testing.TB, template string) {
+	t.Helper()
+
+	for _, render := range r.Renders() {
+		if render.Template == template {
+			t.Errorf("expected template %q not to be rendered", template)
+			return
+		}
+	}
+}
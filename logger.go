@@ -1,6 +1,9 @@
 package leopard
 
-import "github.com/volix-dev/leopard/defaultlogger"
+import (
+	"github.com/volix-dev/leopard/defaultlogger"
+	"github.com/volix-dev/leopard/logging"
+)
 
 var Logger LoggerInterface
 
@@ -24,9 +27,18 @@ func Debug(args ...interface{}) {
 	Logger.Debug(args...)
 }
 
-type LoggerInterface interface {
-	Info(arg ...interface{})
-	Warning(arg ...interface{})
-	Error(arg ...interface{})
-	Debug(arg ...interface{})
+// LoggerInterface is a leveled, structured logger.
+type LoggerInterface = logging.Logger
+
+// Fields are structured key value pairs added to log entries.
+type Fields = logging.Fields
+
+// levelSetter is implemented by loggers that can change their level, like the default logger.
+type levelSetter interface {
+	SetLevel(level string) error
+}
+
+// formatSetter is implemented by loggers that can change their output format, like the default logger.
+type formatSetter interface {
+	SetFormat(format string) error
 }
@@ -17,3 +17,7 @@ func TestWarning(t *testing.T) {
 func TestError(t *testing.T) {
 	Error("This should be an error log")
 }
+
+func TestWithFields(t *testing.T) {
+	Logger.WithFields(Fields{"user": "bob"}).Infof("This should be an info log with a %s", "user field")
+}
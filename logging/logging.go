@@ -0,0 +1,55 @@
+// Package logging defines the logger interface used by leopard and its drivers.
+package logging
+
+import "context"
+
+// Fields are structured key value pairs added to log entries.
+type Fields map[string]interface{}
+
+// Logger is a leveled, structured logger.
+type Logger interface {
+	Info(arg ...interface{})
+	Warning(arg ...interface{})
+	Error(arg ...interface{})
+	Debug(arg ...interface{})
+
+	Infof(format string, args ...interface{})
+	Warningf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Debugf(format string, args ...interface{})
+
+	// WithFields returns a logger that adds the fields to every entry.
+	WithFields(fields Fields) Logger
+
+	// WithContext returns a logger that adds the fields stored in the context to every entry.
+	WithContext(ctx context.Context) Logger
+}
+
+type fieldsKey struct{}
+
+// ContextWithFields returns a context carrying the fields, on top of the fields already in the context.
+// Loggers created with WithContext add these fields to every entry.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	merged := Fields{}
+
+	for key, value := range FieldsFromContext(ctx) {
+		merged[key] = value
+	}
+
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext gets the fields stored in the context.
+func FieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(fieldsKey{}).(Fields)
+
+	return fields
+}
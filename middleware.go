@@ -0,0 +1,101 @@
+package leopard
+
+import (
+	"net/http"
+)
+
+// chainContext is implemented by contexts that can run a middleware chain.
+// Custom contexts get it by embedding *Context.
+type chainContext interface {
+	setHandlers(handlers []HandlerFunc)
+}
+
+// httpContext is implemented by contexts that can replace their request and response writer.
+// Custom contexts get it by embedding *Context.
+type httpContext interface {
+	setRequest(r *http.Request)
+	setResponseWriter(w http.ResponseWriter)
+}
+
+// formContext is implemented by contexts that parse forms with the limits of the app.
+type formContext interface {
+	parseForm(config BindConfig) error
+}
+
+// Use adds global middleware that runs for every route of the app, before the middleware of groups and routes.
+// Middleware has the same signatures as handlers and can call Next to run code after the handler.
+func (a *LeopardApp) Use(middleware ...any) {
+	_, handlers := parseExtras(middleware)
+
+	a.middleware = append(a.middleware, handlers...)
+}
+
+// WrapMiddleware converts a standard net/http middleware to leopard middleware.
+// The rest of the chain runs when the middleware calls the next handler,
+// with the request and response writer it passes along.
+// If the middleware does not call the next handler the chain is aborted.
+func WrapMiddleware(middleware func(http.Handler) http.Handler) HandlerFunc {
+	return func(c ContextInterface) error {
+		var err error
+		called := false
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+
+			if hc, ok := c.(httpContext); ok {
+				hc.setRequest(r)
+				hc.setResponseWriter(w)
+			}
+
+			err = c.Next()
+		})
+
+		middleware(next).ServeHTTP(c.ResponseWriter(), c.Request())
+
+		if !called {
+			c.Abort()
+		}
+
+		return err
+	}
+}
+
+// runChain runs the handlers on the context.
+// Handlers that do not call Next are followed by the next handler once they return.
+func runChain(c ContextInterface, handlers []HandlerFunc) error {
+	if cc, ok := c.(chainContext); ok {
+		cc.setHandlers(handlers)
+
+		return c.Next()
+	}
+
+	// Contexts that do not support Next run the handlers one after another.
+	for _, h := range handlers {
+		if err := h(c); err != nil {
+			return err
+		}
+
+		if c.Aborted() {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// joinHandlers joins the handler lists into a new slice, so appending to the result never changes the inputs.
+func joinHandlers(lists ...[]HandlerFunc) []HandlerFunc {
+	length := 0
+
+	for _, list := range lists {
+		length += len(list)
+	}
+
+	handlers := make([]HandlerFunc, 0, length)
+
+	for _, list := range lists {
+		handlers = append(handlers, list...)
+	}
+
+	return handlers
+}
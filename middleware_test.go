@@ -0,0 +1,135 @@
+package leopard_test
+
+import (
+	"errors"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func trace(steps *[]string, name string) leopard.HandlerFunc {
+	return func(c leopard.ContextInterface) error {
+		*steps = append(*steps, name+":before")
+		err := c.Next()
+		*steps = append(*steps, name+":after")
+
+		return err
+	}
+}
+
+func TestMiddlewareRunsAroundTheHandler(t *testing.T) {
+	a := leopardtest.New(t)
+
+	var steps []string
+
+	a.Use(trace(&steps, "global"))
+
+	a.Group("/api", func(api leopard.RouteGroup) {
+		api.Group("/v1", func(v1 leopard.RouteGroup) {
+			v1.GET("/users", func(c leopard.ContextInterface) {
+				steps = append(steps, "handler")
+			}, trace(&steps, "route"))
+		}, trace(&steps, "v1"))
+	}, trace(&steps, "api"))
+
+	a.Test().GET("/api/v1/users").Expect(t).Status(200)
+
+	expected := "global:before api:before v1:before route:before handler route:after v1:after api:after global:after"
+
+	if actual := strings.Join(steps, " "); actual != expected {
+		t.Fatalf("expected %q, got %q", expected, actual)
+	}
+}
+
+func TestMiddlewareWithoutNextContinues(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/", func(c leopard.ContextInterface) {
+		_, _ = c.WriteString("handler")
+	}, leopard.MiddlewareFunc(func(c leopard.ContextInterface) {
+		c.SetHeader("X-Before", "yes")
+	}))
+
+	a.GET("/aborted", func(c leopard.ContextInterface) {
+		_, _ = c.WriteString("handler")
+	}, func(c leopard.ContextInterface) {
+		c.Unauthorized()
+		c.Abort()
+	})
+
+	client := a.Test()
+
+	client.GET("/").Expect(t).Status(200).Header("X-Before", "yes").BodyEquals("handler")
+	client.GET("/aborted").Expect(t).Status(401).BodyEquals("")
+}
+
+func TestMiddlewareCanRecoverErrors(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.Use(func(c leopard.ContextInterface) error {
+		if err := c.Next(); errors.Is(err, leopard.ErrNotFound) {
+			return c.JsonStatus(404, map[string]string{"error": "nothing here"})
+		}
+
+		return nil
+	})
+
+	a.GET("/", func(c leopard.ContextInterface) error {
+		return leopard.ErrNotFound
+	})
+
+	a.Test().GET("/").Expect(t).Status(404).JSONPath("error", "nothing here")
+}
+
+func TestSwallowedErrorsEndTheChain(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.Use(func(c leopard.ContextInterface) error {
+		if err := c.Next(); err != nil {
+			c.Status(http.StatusUnauthorized)
+		}
+
+		return nil
+	})
+
+	handled := false
+
+	a.GET("/", func(c leopard.ContextInterface) {
+		handled = true
+	}, func(c leopard.ContextInterface) error {
+		return leopard.ErrUnauthorized
+	})
+
+	a.Test().GET("/").Expect(t).Status(401)
+
+	if handled {
+		t.Error("expected the handler not to run after the middleware failed")
+	}
+}
+
+func TestWrapMiddleware(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.Use(leopard.WrapMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Block") != "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			w.Header().Set("X-Wrapped", "yes")
+			next.ServeHTTP(w, r)
+		})
+	}))
+
+	a.GET("/", func(c leopard.ContextInterface) {
+		_, _ = c.WriteString("handler")
+	})
+
+	client := a.Test()
+
+	client.GET("/").Expect(t).Status(200).Header("X-Wrapped", "yes").BodyEquals("handler")
+	client.GET("/").WithHeader("X-Block", "1").Expect(t).Status(403).BodyEquals("")
+}
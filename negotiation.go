@@ -0,0 +1,91 @@
+package leopard
+
+import (
+	"strconv"
+	"strings"
+)
+
+// acceptRange is a single media range of an Accept header.
+type acceptRange struct {
+	mediaType string
+	subType   string
+	quality   float64
+}
+
+// parseAccept parses the media ranges of an Accept header.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaType, subType, _ := strings.Cut(strings.TrimSpace(params[0]), "/")
+
+		if mediaType == "" {
+			continue
+		}
+
+		r := acceptRange{
+			mediaType: strings.ToLower(mediaType),
+			subType:   strings.ToLower(subType),
+			quality:   1,
+		}
+
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+
+			if strings.ToLower(key) == "q" {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					r.quality = q
+				}
+			}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges
+}
+
+// negotiate picks the offered content type the client prefers according to the Accept header.
+// The first offer is used when the header is empty, an empty string is returned when nothing is acceptable.
+func negotiate(header string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(header)
+	best, bestQuality := "", 0.0
+
+	for _, offer := range offers {
+		mediaType, subType, _ := strings.Cut(strings.ToLower(offer), "/")
+		quality, specificity := 0.0, -1
+
+		for _, r := range ranges {
+			s := -1
+
+			switch {
+			case r.mediaType == mediaType && r.subType == subType:
+				s = 2
+			case r.mediaType == mediaType && r.subType == "*":
+				s = 1
+			case r.mediaType == "*" && r.subType == "*":
+				s = 0
+			}
+
+			// The most specific matching range decides the quality of the offer.
+			if s > specificity {
+				quality, specificity = r.quality, s
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = offer, quality
+		}
+	}
+
+	return best
+}
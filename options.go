@@ -1,5 +1,238 @@
 package leopard
 
+import (
+	"github.com/volix-dev/leopard/caching"
+	"github.com/volix-dev/leopard/defaultlogger"
+	"github.com/volix-dev/leopard/files"
+	"github.com/volix-dev/leopard/templating/drivers"
+	"time"
+)
+
+// Options holds the configuration of a LeopardApp.
+// Values that are not set explicitly with an Option are read from the environment.
 type Options struct {
+	// Prefix gets prepended to the path of every route registered on the app.
 	Prefix string
+
+	// ListenAddr is the address the server listens on, defaults to LISTEN.
+	ListenAddr string
+
+	// EnvFile is the .env file to load before reading the environment, defaults to ".env".
+	EnvFile string
+
+	// TemplatePath is the directory the templates are loaded from, defaults to TEMPLATE_PATH.
+	TemplatePath string
+
+	// ShutdownTimeout is the maximum time to wait for in-flight requests on shutdown, defaults to SHUTDOWN_TIMEOUT.
+	ShutdownTimeout time.Duration
+
+	// Server configures the timeouts and limits of the http server.
+	Server ServerConfig
+
+	// TLS configures the certificates used by ServeTLS.
+	TLS TLSConfig
+
+	// Binding configures the size limits of request bodies read by Bind.
+	Binding BindConfig
+
+	// Uploads limits the files that can be uploaded.
+	Uploads UploadConfig
+
+	// Logger is the logger used by the app, defaults to a new default logger for every app.
+	Logger LoggerInterface
+
+	// LogLevel is the minimum level that gets logged, defaults to LOG_LEVEL.
+	// It is applied to loggers that support changing their level, like the default logger.
+	LogLevel string
+
+	// LogFormat is the format of the log entries (text, logfmt or json), defaults to LOG_FORMAT.
+	// It is applied to loggers that support changing their format, like the default logger.
+	LogFormat string
+
+	cacheDriver    caching.Driver
+	fileDriver     files.Driver
+	templateDriver drivers.TemplatingDriver
+}
+
+// Option configures a LeopardApp, it is passed to New.
+type Option func(o *Options)
+
+// WithPrefix prepends the prefix to the path of every route.
+func WithPrefix(prefix string) Option {
+	return func(o *Options) {
+		o.Prefix = prefix
+	}
+}
+
+// WithListenAddr sets the address the server listens on.
+func WithListenAddr(addr string) Option {
+	return func(o *Options) {
+		o.ListenAddr = addr
+	}
+}
+
+// WithEnvFile loads the provided .env file instead of the default one.
+// Unlike the default .env file, New fails when this file can not be loaded.
+func WithEnvFile(path string) Option {
+	return func(o *Options) {
+		o.EnvFile = path
+	}
+}
+
+// WithTemplatePath sets the directory the templates are loaded from.
+func WithTemplatePath(path string) Option {
+	return func(o *Options) {
+		o.TemplatePath = path
+	}
+}
+
+// WithShutdownTimeout sets the maximum time to wait for in-flight requests on shutdown.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.ShutdownTimeout = timeout
+	}
+}
+
+// WithServerConfig sets the timeouts and limits of the http server.
+func WithServerConfig(config ServerConfig) Option {
+	return func(o *Options) {
+		o.Server = config
+	}
+}
+
+// WithTLS sets the certificates used by ServeTLS.
+func WithTLS(config TLSConfig) Option {
+	return func(o *Options) {
+		o.TLS = config
+	}
+}
+
+// WithBindConfig sets the body size limits and decoding options used by Bind.
+func WithBindConfig(config BindConfig) Option {
+	return func(o *Options) {
+		o.Binding = config
+	}
+}
+
+// WithUploadConfig sets the limits of uploaded files.
+func WithUploadConfig(config UploadConfig) Option {
+	return func(o *Options) {
+		o.Uploads = config
+	}
+}
+
+// WithLogger sets the logger used by the app.
+func WithLogger(logger LoggerInterface) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// WithLogLevel sets the minimum level that gets logged.
+func WithLogLevel(level string) Option {
+	return func(o *Options) {
+		o.LogLevel = level
+	}
+}
+
+// WithLogFormat sets the format of the log entries, it is one of text, logfmt or json.
+func WithLogFormat(format string) Option {
+	return func(o *Options) {
+		o.LogFormat = format
+	}
+}
+
+// WithCache uses the provided caching driver instead of the one configured by CACHE_DRIVER.
+func WithCache(driver caching.Driver) Option {
+	return func(o *Options) {
+		o.cacheDriver = driver
+	}
+}
+
+// WithFileDriver uses the provided file driver instead of the one configured by FILE_DRIVER.
+func WithFileDriver(driver files.Driver) Option {
+	return func(o *Options) {
+		o.fileDriver = driver
+	}
+}
+
+// WithTemplateDriver uses the provided templating driver instead of twig.
+func WithTemplateDriver(driver drivers.TemplatingDriver) Option {
+	return func(o *Options) {
+		o.templateDriver = driver
+	}
+}
+
+// newOptions applies the options and fills in the missing values from the environment.
+// The environment file gets loaded first so its values can be used as defaults.
+func newOptions(opts []Option) (*Options, error) {
+	o := &Options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := loadEnvFile(o.EnvFile); err != nil {
+		return nil, err
+	}
+
+	if o.ListenAddr == "" {
+		o.ListenAddr = EnvSettingD("LISTEN", ":8080").GetValue().(string)
+	}
+
+	if o.TemplatePath == "" {
+		o.TemplatePath = EnvSettingD("TEMPLATE_PATH", "./templates").GetValue().(string)
+	}
+
+	if o.ShutdownTimeout == 0 {
+		timeout, err := envDuration("SHUTDOWN_TIMEOUT", "30s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		o.ShutdownTimeout = timeout
+	}
+
+	if err := o.Server.fillFromEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := o.TLS.fillFromEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := o.Binding.fillFromEnv(); err != nil {
+		return nil, err
+	}
+
+	if err := o.Uploads.fillFromEnv(); err != nil {
+		return nil, err
+	}
+
+	if o.Logger == nil {
+		o.Logger = defaultlogger.New()
+	}
+
+	if o.LogLevel == "" {
+		o.LogLevel = EnvSettingD("LOG_LEVEL", "debug").GetValue().(string)
+	}
+
+	if o.LogFormat == "" {
+		o.LogFormat = EnvSettingD("LOG_FORMAT", "text").GetValue().(string)
+	}
+
+	if setter, ok := o.Logger.(levelSetter); ok {
+		if err := setter.SetLevel(o.LogLevel); err != nil {
+			return nil, err
+		}
+	}
+
+	if setter, ok := o.Logger.(formatSetter); ok {
+		if err := setter.SetFormat(o.LogFormat); err != nil {
+			return nil, err
+		}
+	}
+
+	return o, nil
 }
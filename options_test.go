@@ -0,0 +1,142 @@
+package leopard
+
+import (
+	"bytes"
+	"github.com/gorilla/mux"
+	"github.com/volix-dev/leopard/caching"
+	"github.com/volix-dev/leopard/defaultlogger"
+	"github.com/volix-dev/leopard/files"
+	"github.com/volix-dev/leopard/templating/drivers"
+	"io"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+type nopTemplateDriver struct{}
+
+func (n nopTemplateDriver) RenderTemplate(template string, writer io.Writer, data map[string]drivers.Value) error {
+	return nil
+}
+
+func (n nopTemplateDriver) Load(path string, router *mux.Router) error {
+	return nil
+}
+
+func newTestApp(t *testing.T, opts ...Option) *LeopardApp {
+	cache, err := caching.New("memory", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileDriver, err := files.Get("os", t.TempDir())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(append([]Option{
+		WithCache(cache),
+		WithFileDriver(fileDriver),
+		WithTemplateDriver(nopTemplateDriver{}),
+	}, opts...)...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = a.Close()
+	})
+
+	return a
+}
+
+func TestNewWithOptions(t *testing.T) {
+	a := newTestApp(t, WithListenAddr(":9090"), WithShutdownTimeout(time.Second), WithPrefix("/api"))
+
+	if a.ListenAddr != ":9090" {
+		t.Errorf("expected listen address :9090, got %s", a.ListenAddr)
+	}
+
+	if a.ShutdownTimeout != time.Second {
+		t.Errorf("expected shutdown timeout of 1s, got %s", a.ShutdownTimeout)
+	}
+
+	if _, ok := a.TemplateDriver.(nopTemplateDriver); !ok {
+		t.Errorf("expected the provided template driver, got %T", a.TemplateDriver)
+	}
+
+	a.GET("/users", func(c ContextInterface) {}, "users")
+
+	url, err := a.GetRouter().Get("users").URL()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if url.Path != "/api/users" {
+		t.Errorf("expected the route to be prefixed, got %s", url.Path)
+	}
+}
+
+func TestNewUsesEnvironmentAsDefault(t *testing.T) {
+	t.Setenv("LISTEN", ":9999")
+	t.Setenv("SHUTDOWN_TIMEOUT", "5s")
+
+	a := newTestApp(t)
+
+	if a.ListenAddr != ":9999" {
+		t.Errorf("expected listen address from the environment, got %s", a.ListenAddr)
+	}
+
+	if a.ShutdownTimeout != 5*time.Second {
+		t.Errorf("expected shutdown timeout from the environment, got %s", a.ShutdownTimeout)
+	}
+}
+
+func TestNewFailsOnMissingEnvFile(t *testing.T) {
+	_, err := New(WithEnvFile(path.Join(t.TempDir(), "missing.env")))
+
+	if err == nil {
+		t.Fatal("expected an error for a missing env file")
+	}
+}
+
+func TestServerTimeouts(t *testing.T) {
+	a := newTestApp(t, WithServerConfig(ServerConfig{WriteTimeout: NoTimeout}))
+	server := NewSimpleServer(":0", a.Server)
+
+	if server.WriteTimeout != 0 {
+		t.Errorf("expected no write timeout, got %s", server.WriteTimeout)
+	}
+
+	if server.ReadTimeout != 30*time.Second {
+		t.Errorf("expected the default read timeout, got %s", server.ReadTimeout)
+	}
+}
+
+func TestLogOptionsOnlyChangeTheApp(t *testing.T) {
+	first := newTestApp(t, WithLogLevel("error"))
+	second := newTestApp(t, WithLogLevel("debug"), WithLogFormat("json"))
+
+	if first.Options.Logger == Logger || first.Options.Logger == second.Options.Logger {
+		t.Fatal("expected every app to get its own logger")
+	}
+
+	output := &bytes.Buffer{}
+	first.Options.Logger.(*defaultlogger.LogrusLogger).SetOutput(output)
+	first.logger().Info("hidden")
+
+	if output.Len() != 0 {
+		t.Errorf("expected the second app not to change the level of the first, got %q", output.String())
+	}
+
+	first.logger().Error("shown")
+
+	if strings.HasPrefix(output.String(), "{") || !strings.Contains(output.String(), "shown") {
+		t.Errorf("expected the second app not to change the format of the first, got %q", output.String())
+	}
+}
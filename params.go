@@ -0,0 +1,322 @@
+package leopard
+
+import (
+	"fmt"
+	"github.com/volix-dev/leopard/helpers"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParamError is returned when a path or query parameter is missing or can not be converted.
+// It responds with a 400 Bad Request.
+type ParamError struct {
+	// Source is where the parameter comes from, either "path" or "query".
+	Source string
+
+	// Name is the name of the parameter.
+	Name string
+
+	// Value is the raw value of the parameter.
+	Value string
+
+	// Expected describes the expected type, for example "an integer".
+	Expected string
+}
+
+func (e *ParamError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("missing %s parameter %q", e.Source, e.Name)
+	}
+
+	return fmt.Sprintf("invalid %s parameter %q: %q is not %s", e.Source, e.Name, e.Value, e.Expected)
+}
+
+// StatusCode makes the error handler respond with a 400.
+func (e *ParamError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Unwrap makes the error match ErrBadRequest.
+func (e *ParamError) Unwrap() error {
+	return ErrBadRequest
+}
+
+// Converter constrains a route parameter and converts its value.
+// It is used in route patterns by name, for example /users/{id:int}.
+type Converter struct {
+	// Pattern is the regular expression the parameter has to match.
+	Pattern string
+
+	// Convert converts the matched value, the result is available through Context.Param.
+	Convert func(value string) (any, error)
+
+	// Expected describes the converted type in errors, for example "an integer".
+	Expected string
+}
+
+var (
+	converters     = map[string]Converter{}
+	convertersLock sync.RWMutex
+)
+
+func init() {
+	RegisterConverter("int", Converter{
+		Pattern:  `-?[0-9]+`,
+		Expected: "an integer",
+		Convert: func(value string) (any, error) {
+			return strconv.Atoi(value)
+		},
+	})
+
+	RegisterConverter("uint", Converter{
+		Pattern:  `[0-9]+`,
+		Expected: "a positive integer",
+		Convert: func(value string) (any, error) {
+			i, err := strconv.ParseUint(value, 10, 0)
+
+			return uint(i), err
+		},
+	})
+
+	RegisterConverter("uuid", Converter{
+		Pattern:  `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+		Expected: "a UUID",
+		Convert: func(value string) (any, error) {
+			return helpers.ParseUUID(value)
+		},
+	})
+
+	RegisterConverter("slug", Converter{
+		Pattern:  `[a-z0-9]+(?:-[a-z0-9]+)*`,
+		Expected: "a slug",
+	})
+
+	RegisterConverter("path", Converter{
+		Pattern:  `.+`,
+		Expected: "a path",
+	})
+}
+
+// RegisterConverter registers a converter that can be used in route patterns by name.
+// Converters without a Convert function only constrain the parameter.
+func RegisterConverter(name string, converter Converter) {
+	convertersLock.Lock()
+	defer convertersLock.Unlock()
+
+	converters[name] = converter
+}
+
+// expandConverters replaces the converter names in the route pattern with their regular expressions.
+// It returns the converters of the parameters, other regular expressions are left as is.
+func expandConverters(pattern string) (string, map[string]Converter, error) {
+	convertersLock.RLock()
+	defer convertersLock.RUnlock()
+
+	var builder strings.Builder
+	found := map[string]Converter{}
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '{' {
+			builder.WriteByte(pattern[i])
+			continue
+		}
+
+		// Find the matching brace, regular expressions can contain braces themselves.
+		depth, end := 0, -1
+
+		for j := i; j < len(pattern) && end < 0; j++ {
+			switch pattern[j] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+
+				if depth == 0 {
+					end = j
+				}
+			}
+		}
+
+		if end < 0 {
+			return "", nil, fmt.Errorf("unbalanced braces in route %q", pattern)
+		}
+
+		name, expression, hasExpression := strings.Cut(pattern[i+1:end], ":")
+		converter, ok := converters[expression]
+
+		if hasExpression && ok {
+			found[name] = converter
+			builder.WriteString("{" + name + ":" + converter.Pattern + "}")
+		} else {
+			builder.WriteString(pattern[i : end+1])
+		}
+
+		i = end
+	}
+
+	return builder.String(), found, nil
+}
+
+// paramContext is implemented by contexts that store converted route parameters.
+// Custom contexts get it by embedding *Context.
+type paramContext interface {
+	setParam(key string, value any)
+}
+
+// convertParams is the middleware that converts the route parameters before the handler runs.
+func convertParams(found map[string]Converter) HandlerFunc {
+	return func(c ContextInterface) error {
+		pc, ok := c.(paramContext)
+
+		if !ok {
+			return nil
+		}
+
+		for name, converter := range found {
+			value := c.GetParam(name)
+
+			if converter.Convert == nil {
+				pc.setParam(name, value)
+				continue
+			}
+
+			converted, err := converter.Convert(value)
+
+			if err != nil {
+				return &ParamError{Source: "path", Name: name, Value: value, Expected: converter.Expected}
+			}
+
+			pc.setParam(name, converted)
+		}
+
+		return nil
+	}
+}
+
+// Typed path parameters
+
+// Param gets the converted value of a route parameter, or the raw string when the route has no converter for it.
+// It returns nil when the parameter does not exist.
+func (c *Context) Param(key string) any {
+	if value, ok := c.params[key]; ok {
+		return value
+	}
+
+	if value, ok := c.vars[key]; ok {
+		return value
+	}
+
+	return nil
+}
+
+func (c *Context) setParam(key string, value any) {
+	if c.params == nil {
+		c.params = map[string]any{}
+	}
+
+	c.params[key] = value
+}
+
+// ParamInt gets a route parameter as an integer.
+func (c *Context) ParamInt(key string) (int, error) {
+	if value, ok := c.params[key].(int); ok {
+		return value, nil
+	}
+
+	value := c.GetParam(key)
+	i, err := strconv.Atoi(value)
+
+	if err != nil {
+		return 0, &ParamError{Source: "path", Name: key, Value: value, Expected: "an integer"}
+	}
+
+	return i, nil
+}
+
+// ParamUUID gets a route parameter as a UUID.
+func (c *Context) ParamUUID(key string) (helpers.UUID, error) {
+	if value, ok := c.params[key].(helpers.UUID); ok {
+		return value, nil
+	}
+
+	value := c.GetParam(key)
+	uuid, err := helpers.ParseUUID(value)
+
+	if err != nil {
+		return uuid, &ParamError{Source: "path", Name: key, Value: value, Expected: "a UUID"}
+	}
+
+	return uuid, nil
+}
+
+// ParamTime gets a route parameter as a time in the layout, for example "2006-01-02".
+func (c *Context) ParamTime(key string, layout string) (time.Time, error) {
+	value := c.GetParam(key)
+	t, err := time.Parse(layout, value)
+
+	if err != nil {
+		return t, &ParamError{Source: "path", Name: key, Value: value, Expected: "a time in the format " + layout}
+	}
+
+	return t, nil
+}
+
+// Typed query parameters
+
+// QueryInt gets a query parameter as an integer, the default value is returned when it is not set.
+func (c *Context) QueryInt(key string, defaultValue int) (int, error) {
+	value := c.GetQuery(key)
+
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	i, err := strconv.Atoi(value)
+
+	if err != nil {
+		return defaultValue, &ParamError{Source: "query", Name: key, Value: value, Expected: "an integer"}
+	}
+
+	return i, nil
+}
+
+// QueryBool gets a query parameter as a boolean, the default value is returned when it is not set.
+// A parameter without a value, like ?verbose, is true.
+func (c *Context) QueryBool(key string, defaultValue bool) (bool, error) {
+	values, ok := c.request.URL.Query()[key]
+
+	if !ok {
+		return defaultValue, nil
+	}
+
+	if len(values) == 0 || values[0] == "" {
+		return true, nil
+	}
+
+	b, err := strconv.ParseBool(values[0])
+
+	if err != nil {
+		return defaultValue, &ParamError{Source: "query", Name: key, Value: values[0], Expected: "a boolean"}
+	}
+
+	return b, nil
+}
+
+// QuerySlice gets all the values of a query parameter.
+// Both repeated parameters (?tag=a&tag=b) and comma separated values (?tag=a,b) are supported.
+func (c *Context) QuerySlice(key string) []string {
+	var values []string
+
+	for _, value := range c.request.URL.Query()[key] {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,130 @@
+package leopard_test
+
+import (
+	"fmt"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/helpers"
+	"github.com/volix-dev/leopard/leopardtest"
+	"strings"
+	"testing"
+)
+
+func TestRouteConverters(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/users/{id:int}", func(c leopard.ContextInterface) error {
+		id, ok := c.Param("id").(int)
+
+		if !ok {
+			return fmt.Errorf("expected an int, got %T", c.Param("id"))
+		}
+
+		_, err := c.WriteString(fmt.Sprint(id + 1))
+
+		return err
+	})
+
+	a.GET("/files/{path:path}", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString(c.GetParam("path"))
+
+		return err
+	})
+
+	a.GET("/items/{id:uuid}", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString(c.Param("id").(helpers.UUID).String())
+
+		return err
+	})
+
+	client := a.Test()
+
+	client.GET("/users/41").Expect(t).Status(200).BodyEquals("42")
+	client.GET("/users/abc").Expect(t).Status(404)
+	client.GET("/users/99999999999999999999999").Expect(t).Status(400)
+	client.GET("/files/css/app.css").Expect(t).Status(200).BodyEquals("css/app.css")
+	client.GET("/items/6BA7B810-9DAD-11D1-80B4-00C04FD430C8").Expect(t).Status(200).BodyEquals("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	client.GET("/items/6ba7b810").Expect(t).Status(404)
+}
+
+func TestCustomConverter(t *testing.T) {
+	leopard.RegisterConverter("upper", leopard.Converter{
+		Pattern:  `[a-z]+`,
+		Expected: "lowercase letters",
+		Convert: func(value string) (any, error) {
+			return strings.ToUpper(value), nil
+		},
+	})
+
+	a := leopardtest.New(t)
+
+	a.GET("/shout/{word:upper}/{n:[0-9]{2}}", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString(c.Param("word").(string) + c.GetParam("n"))
+
+		return err
+	})
+
+	client := a.Test()
+
+	client.GET("/shout/hello/42").Expect(t).Status(200).BodyEquals("HELLO42")
+	client.GET("/shout/hello/4").Expect(t).Status(404)
+}
+
+func TestTypedParams(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/orders/{id}/{date}", func(c leopard.ContextInterface) error {
+		id, err := c.ParamInt("id")
+
+		if err != nil {
+			return err
+		}
+
+		date, err := c.ParamTime("date", "2006-01-02")
+
+		if err != nil {
+			return err
+		}
+
+		_, err = c.WriteString(fmt.Sprintf("%d %s", id, date.Weekday()))
+
+		return err
+	})
+
+	client := a.Test()
+
+	client.GET("/orders/7/2024-01-01").Expect(t).Status(200).BodyEquals("7 Monday")
+	client.GET("/orders/seven/2024-01-01").Expect(t).Status(400).
+		JSONPath("message", `invalid path parameter "id": "seven" is not an integer`)
+	client.GET("/orders/7/yesterday").Expect(t).Status(400)
+}
+
+func TestTypedQueries(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/search", func(c leopard.ContextInterface) error {
+		page, err := c.QueryInt("page", 1)
+
+		if err != nil {
+			return err
+		}
+
+		exact, err := c.QueryBool("exact", false)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = c.WriteString(fmt.Sprintf("%d %t %s", page, exact, strings.Join(c.QuerySlice("tag"), "|")))
+
+		return err
+	})
+
+	client := a.Test()
+
+	client.GET("/search").Expect(t).Status(200).BodyEquals("1 false ")
+	client.GET("/search?page=3&exact&tag=a,b&tag=c").Expect(t).Status(200).BodyEquals("3 true a|b|c")
+	client.GET("/search?exact=0").Expect(t).Status(200).BodyEquals("1 false ")
+	client.GET("/search?page=two").Expect(t).Status(400).
+		JSONPath("message", `invalid query parameter "page": "two" is not an integer`)
+	client.GET("/search?exact=maybe").Expect(t).Status(400)
+}
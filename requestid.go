@@ -0,0 +1,123 @@
+package leopard
+
+import (
+	"context"
+	"github.com/volix-dev/leopard/helpers"
+	"net/http"
+)
+
+// RequestIDHeader is the default header the request ID is read from and written to.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestIDConfig configures the RequestIDMiddleware.
+type RequestIDConfig struct {
+	// Header is the request and response header of the request ID, defaults to X-Request-ID.
+	Header string
+
+	// Generator creates new request IDs, defaults to ULIDs.
+	Generator func() string
+
+	// IgnoreIncoming always generates a new ID instead of using the one sent by the client.
+	IgnoreIncoming bool
+}
+
+// RequestIDMiddleware gives every request an ID, it uses the ID from the request header when there is a valid one.
+// The ID is stored in the request context, added to the response headers, to the entries of the request logger
+// and to error responses.
+func RequestIDMiddleware(config RequestIDConfig) HandlerFunc {
+	if config.Header == "" {
+		config.Header = RequestIDHeader
+	}
+
+	if config.Generator == nil {
+		config.Generator = helpers.NewULID
+	}
+
+	return func(c ContextInterface) error {
+		id := ""
+
+		if !config.IgnoreIncoming {
+			id = c.GetHeader(config.Header)
+		}
+
+		if !validRequestID(id) {
+			id = config.Generator()
+		}
+
+		if hc, ok := c.(httpContext); ok {
+			hc.setRequest(c.Request().WithContext(ContextWithRequestID(c.Request().Context(), id)))
+		}
+
+		c.AddLogFields(Fields{"request_id": id})
+		c.SetHeader(config.Header, id)
+
+		return c.Next()
+	}
+}
+
+// validRequestID checks if an incoming ID is safe to use in headers and logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// ContextWithRequestID returns a context carrying the request ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext gets the request ID from the context, or an empty string when there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
+
+// RequestIDTransport is an http.RoundTripper that adds the request ID of the request context to outgoing requests.
+// Create outgoing requests with the context of the incoming request to propagate its ID:
+//
+//	client := &http.Client{Transport: &leopard.RequestIDTransport{}}
+//	req, _ := http.NewRequestWithContext(c.Request().Context(), http.MethodGet, url, nil)
+type RequestIDTransport struct {
+	// Base is the transport that makes the request, defaults to http.DefaultTransport.
+	Base http.RoundTripper
+
+	// Header is the header the ID is sent in, defaults to X-Request-ID.
+	Header string
+}
+
+func (t *RequestIDTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	header := t.Header
+
+	if header == "" {
+		header = RequestIDHeader
+	}
+
+	if id := RequestIDFromContext(r.Context()); id != "" && r.Header.Get(header) == "" {
+		// A RoundTripper must not modify the original request.
+		r = r.Clone(r.Context())
+		r.Header.Set(header, id)
+	}
+
+	return base.RoundTrip(r)
+}
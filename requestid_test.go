@@ -0,0 +1,84 @@
+package leopard_test
+
+import (
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+var ulidPattern = regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+
+func TestRequestIDIsGenerated(t *testing.T) {
+	a := leopardtest.New(t)
+	a.Use(leopard.RequestIDMiddleware(leopard.RequestIDConfig{}))
+
+	var seen string
+
+	a.GET("/", func(c leopard.ContextInterface) error {
+		seen = c.RequestID()
+
+		return leopard.ErrConflict
+	})
+
+	response := a.Test().GET("/").Expect(t).Status(409)
+	id := response.Response.Header.Get(leopard.RequestIDHeader)
+
+	if !ulidPattern.MatchString(id) {
+		t.Fatalf("expected a ULID request ID, got %q", id)
+	}
+
+	if seen != id {
+		t.Errorf("expected the handler to see request ID %q, got %q", id, seen)
+	}
+
+	response.JSONPath("request_id", id)
+}
+
+func TestRequestIDFromIncomingHeader(t *testing.T) {
+	a := leopardtest.New(t)
+	a.Use(leopard.RequestIDMiddleware(leopard.RequestIDConfig{}))
+
+	a.GET("/", func(c leopard.ContextInterface) {
+		_, _ = c.WriteString(c.RequestID())
+	})
+
+	client := a.Test()
+
+	client.GET("/").WithHeader("X-Request-ID", "abc-123").Expect(t).
+		Header("X-Request-ID", "abc-123").
+		BodyEquals("abc-123")
+
+	id := client.GET("/").WithHeader("X-Request-ID", "evil\nlog line").Expect(t).BodyString()
+
+	if !ulidPattern.MatchString(id) {
+		t.Errorf("expected an invalid incoming ID to be replaced, got %q", id)
+	}
+}
+
+func TestRequestIDTransport(t *testing.T) {
+	var received string
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-Request-ID")
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: &leopard.RequestIDTransport{}}
+	ctx := leopard.ContextWithRequestID(httptest.NewRequest(http.MethodGet, "/", nil).Context(), "abc-123")
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL, nil)
+
+	response, err := client.Do(request)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = response.Body.Close()
+
+	if received != "abc-123" {
+		t.Errorf("expected the request ID to be propagated, got %q", received)
+	}
+}
@@ -0,0 +1,129 @@
+package leopard
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Response wraps an http.ResponseWriter and keeps track of the status code and the number of bytes written.
+// Once the headers are written they can not be changed anymore, calling WriteHeader again is ignored.
+type Response struct {
+	writer      http.ResponseWriter
+	status      int
+	size        int64
+	written     bool
+	beforeWrite []func()
+}
+
+// NewResponse wraps the response writer, a writer that already is a *Response is returned as is.
+func NewResponse(w http.ResponseWriter) *Response {
+	if r, ok := w.(*Response); ok {
+		return r
+	}
+
+	return &Response{
+		writer: w,
+		status: http.StatusOK,
+	}
+}
+
+// Header returns the response headers, they can be changed until the headers are written.
+func (r *Response) Header() http.Header {
+	return r.writer.Header()
+}
+
+// WriteHeader writes the headers with the status code.
+// The before write hooks run first, calls after the headers were written are ignored.
+func (r *Response) WriteHeader(status int) {
+	if r.written {
+		return
+	}
+
+	hooks := r.beforeWrite
+	r.beforeWrite = nil
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	r.status = status
+	r.written = true
+	r.writer.WriteHeader(status)
+}
+
+// Write writes the data to the response, the headers are written with a 200 first if they were not yet.
+func (r *Response) Write(data []byte) (int, error) {
+	if !r.written {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	n, err := r.writer.Write(data)
+	r.size += int64(n)
+
+	return n, err
+}
+
+// Before registers a hook that runs right before the headers are written.
+// Hooks run in order of registration and can still change the headers.
+func (r *Response) Before(hook func()) {
+	r.beforeWrite = append(r.beforeWrite, hook)
+}
+
+// StatusCode gets the status code that was written, or 200 if the headers were not written yet.
+func (r *Response) StatusCode() int {
+	return r.status
+}
+
+// BytesWritten gets the number of bytes of the body that were written.
+func (r *Response) BytesWritten() int64 {
+	return r.size
+}
+
+// Written returns true when the headers were written, after that the status code can not change anymore.
+func (r *Response) Written() bool {
+	return r.written
+}
+
+// Unwrap returns the wrapped response writer, it is used by http.ResponseController.
+func (r *Response) Unwrap() http.ResponseWriter {
+	return r.writer
+}
+
+// Flush sends the buffered data to the client, if the wrapped response writer supports it.
+func (r *Response) Flush() {
+	if !r.written {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := r.writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, if the wrapped response writer supports it.
+func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.writer.(http.Hijacker)
+
+	if !ok {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+
+	if err == nil {
+		r.written = true
+	}
+
+	return conn, rw, err
+}
+
+// Push initiates an HTTP/2 server push, if the wrapped response writer supports it.
+func (r *Response) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := r.writer.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+
+	return http.ErrNotSupported
+}
@@ -0,0 +1,57 @@
+package leopard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseTracksStatusAndBytes(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	response := NewResponse(recorder)
+
+	if response.Written() {
+		t.Fatal("expected a new response not to be written")
+	}
+
+	response.WriteHeader(http.StatusCreated)
+	response.WriteHeader(http.StatusInternalServerError)
+	_, _ = response.Write([]byte("hello"))
+
+	if response.StatusCode() != http.StatusCreated || recorder.Code != http.StatusCreated {
+		t.Errorf("expected the first status code to be kept, got %d", response.StatusCode())
+	}
+
+	if response.BytesWritten() != 5 {
+		t.Errorf("expected 5 bytes written, got %d", response.BytesWritten())
+	}
+
+	if NewResponse(response) != response {
+		t.Error("expected an existing response not to be wrapped again")
+	}
+}
+
+func TestResponseBeforeHooks(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	response := NewResponse(recorder)
+
+	response.Before(func() {
+		response.Header().Set("X-Late", "yes")
+	})
+
+	_, _ = response.Write([]byte("body"))
+
+	if recorder.Header().Get("X-Late") != "yes" {
+		t.Error("expected the hook to set the header before writing")
+	}
+
+	response.Flush()
+
+	if !recorder.Flushed {
+		t.Error("expected the flush to be passed to the wrapped writer")
+	}
+
+	if err := response.Push("/app.css", nil); err != http.ErrNotSupported {
+		t.Errorf("expected push not to be supported by the recorder, got %v", err)
+	}
+}
@@ -1,39 +1,80 @@
 package leopard
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/volix-dev/leopard/static"
+	"github.com/volix-dev/leopard/templating/drivers"
+	"io/fs"
 	"net/http"
 	"os"
 	"path"
-	"regexp"
 	"strings"
 )
 
+// MiddlewareFunc is middleware that can not fail.
+// Like all middleware it can call Next to run the rest of the chain and then run code after the handler,
+// middleware that does not call Next is followed by the rest of the chain once it returns.
 type MiddlewareFunc func(context ContextInterface)
 
-// GET handler register
-func (a *LeopardApp) GET(p string, h func(r ContextInterface), extras ...any) {
+// Handler handles a route, it is either a func(ContextInterface) or a func(ContextInterface) error.
+// Errors returned by a handler are passed to the app's ErrorHandler.
+type Handler interface{}
+
+// HandlerFunc is a handler that can fail, returned errors are passed to the app's ErrorHandler.
+// It can be used both as route handler and as middleware.
+type HandlerFunc func(c ContextInterface) error
+
+// toHandlerFunc converts the supported handler types to a HandlerFunc.
+// It panics on other types, so mistakes are found when the route is registered.
+func toHandlerFunc(h Handler) HandlerFunc {
+	switch h := h.(type) {
+	case HandlerFunc:
+		return h
+
+	case func(ContextInterface) error:
+		return h
+
+	case MiddlewareFunc:
+		return func(c ContextInterface) error {
+			h(c)
+			return nil
+		}
+
+	case func(ContextInterface):
+		return func(c ContextInterface) error {
+			h(c)
+			return nil
+		}
+	}
+
+	panic(fmt.Sprintf("invalid handler type %T, expected func(ContextInterface) or func(ContextInterface) error", h))
+}
+
+// GET handler register, the handler also answers HEAD requests for the path.
+func (a *LeopardApp) GET(p string, h Handler, extras ...any) {
 	a.AddRoute(http.MethodGet, p, h, extras...)
 }
 
 // POST register a route with the method POST
-func (a *LeopardApp) POST(p string, h func(r ContextInterface), extras ...any) {
+func (a *LeopardApp) POST(p string, h Handler, extras ...any) {
 	a.AddRoute(http.MethodPost, p, h, extras...)
 }
 
 // PUT register a route with the method PUT
-func (a *LeopardApp) PUT(p string, h func(r ContextInterface), extras ...any) {
-	a.AddRoute(http.MethodPut, p, h, extras)
+func (a *LeopardApp) PUT(p string, h Handler, extras ...any) {
+	a.AddRoute(http.MethodPut, p, h, extras...)
 }
 
 // DELETE register a route with the method DELETE
-func (a *LeopardApp) DELETE(p string, h func(r ContextInterface), extras ...any) {
-	a.AddRoute(http.MethodDelete, p, h, extras)
+func (a *LeopardApp) DELETE(p string, h Handler, extras ...any) {
+	a.AddRoute(http.MethodDelete, p, h, extras...)
 }
 
 // PATCH register a reoute with the method PATCH
-func (a *LeopardApp) PATCH(p string, h func(r ContextInterface), extras ...any) {
-	a.AddRoute(http.MethodPatch, p, h, extras)
+func (a *LeopardApp) PATCH(p string, h Handler, extras ...any) {
+	a.AddRoute(http.MethodPatch, p, h, extras...)
 }
 
 func (a *LeopardApp) Group(p string, groupHandler func(group RouteGroup), extras ...any) RouteGroup {
@@ -43,6 +84,7 @@ func (a *LeopardApp) Group(p string, groupHandler func(group RouteGroup), extras
 		namePrefix: name,
 		app:        a,
 		middleware: middleware,
+		flags:      parseFlags(extras),
 	}
 	groupHandler(group)
 
@@ -52,96 +94,164 @@ func (a *LeopardApp) Group(p string, groupHandler func(group RouteGroup), extras
 // AddRoute adds a route to the route manager
 // This is mainly called by methods as GET, POST, PUT, DELETE and PATCH
 // However if needed a user could register a custom method name (or one we did not include)
-func (a *LeopardApp) AddRoute(method string, p string, h func(r ContextInterface), extras ...any) {
+//
+// The handler is either a func(ContextInterface) or a func(ContextInterface) error.
+// The extras can be a route name (string), middleware with the same signatures and flags like CSRFExempt.
+func (a *LeopardApp) AddRoute(method string, p string, h Handler, extras ...any) {
 	name, middleware := parseExtras(extras)
 
-	a.addRoute(method, p, h, name, middleware)
+	a.addRoute(method, p, toHandlerFunc(h), name, middleware, parseFlags(extras))
 }
 
-func (a *LeopardApp) addRoute(method string, p string, h func(r ContextInterface), name *string, middleware []MiddlewareFunc) {
+func (a *LeopardApp) addRoute(method string, p string, h HandlerFunc, name *string, middleware []HandlerFunc, flags []RouteFlag) {
+	pattern, found, err := expandConverters(a.withPrefix(p))
+
+	if err != nil {
+		panic(err)
+	}
+
+	if len(found) > 0 {
+		middleware = joinHandlers(middleware, []HandlerFunc{convertParams(found)})
+	}
+
 	r := a.router.NewRoute()
 
-	r.Methods(method)
-	r.Path(p)
+	// HEAD requests are served by the GET route, without the body.
+	if method == http.MethodGet {
+		r.Methods(http.MethodGet, http.MethodHead)
+	} else {
+		r.Methods(method)
+	}
+
+	r.Path(pattern)
 
-	fmt.Println(r.GetPathTemplate())
+	a.logger().Debugf("registered route %s %s", method, a.withPrefix(p))
 
 	if name != nil {
 		r.Name(*name)
 	}
 
 	r.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		context := a.ContextCreator(r, w, a)
+		if len(flags) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), routeFlagsKey{}, flags))
+		}
 
-		defer func() {
-			if r := recover(); r != nil {
-				err := context.Error(fmt.Errorf("%v", r))
+		a.serveChain(w, r, joinHandlers(a.middleware, middleware, []HandlerFunc{h}))
+	})
+}
 
-				if err != nil {
-					return
-				}
-			}
-		}()
+// serveChain runs the handlers for the request with a new context.
+// Errors and panics are passed to the error handler of the app.
+func (a *LeopardApp) serveChain(w http.ResponseWriter, r *http.Request, handlers []HandlerFunc) {
+	if r.Method == http.MethodHead {
+		w = headWriter{w}
+	}
+
+	context := a.ContextCreator(r, NewResponse(w), a)
 
-		for _, m := range middleware {
-			m(context)
+	// The form is parsed on a copy of the request, so net/http does not remove its temporary files.
+	defer func() {
+		if form := context.Request().MultipartForm; form != nil {
+			_ = form.RemoveAll()
+		}
+	}()
 
-			if context.Aborted() {
-				return
+	defer func() {
+		if r := recover(); r != nil {
+			if r == http.ErrAbortHandler {
+				panic(r)
 			}
+
+			_ = context.Error(panicError(r))
 		}
+	}()
 
-		h(context)
-	})
+	if err := runChain(context, handlers); err != nil {
+		_ = context.Error(err)
+	}
 }
 
-// StaticDir register a static directory
+// panicError converts a recovered value to an error for the error handler.
+func panicError(r any) error {
+	if err, ok := r.(error); ok {
+		return err
+	}
+
+	return fmt.Errorf("%v", r)
+}
+
+// StaticDir serves the files of a directory on the disk under the path.
+// Use StaticFS for embedded files or to configure the server.
 func (a *LeopardApp) StaticDir(p string, root string) {
-	h := a.fileServer(root, p)
-	a.router.PathPrefix(p).Handler(h)
-}
-
-// fileServer creates a file server and returns its handler
-func (a *LeopardApp) fileServer(rootDir string, p string) http.Handler {
-	baseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		cleanedPath := regexp.MustCompile("\\.\\.\\/|~").ReplaceAllString(
-			strings.TrimPrefix(r.URL.Path, p),
-			"",
-		)
-		f, err := os.Open(path.Join(rootDir,
-			strings.ReplaceAll(
-				cleanedPath,
-				"../",
-				"",
-			)),
-		)
-
-		if os.IsNotExist(err) {
-			w.WriteHeader(404)
-
-			return
+	a.StaticFS(p, os.DirFS(root), static.Config{})
+}
+
+// StaticFS serves the files of the file system under the path, for example an embed.FS.
+// Directories are served with their index file, or listed when Browse is set in the config.
+// Hidden files are never served, except for the .well-known directory.
+// The routes run the middleware of the app, missing files are passed to the ErrorHandler as ErrNotFound.
+// With SPA enabled, register the static files after the other routes, so the other routes are matched first.
+func (a *LeopardApp) StaticFS(p string, fsys fs.FS, config static.Config) {
+	server := static.NewServer(fsys, config)
+
+	handler := func(c ContextInterface) error {
+		err := server.Serve(c.ResponseWriter(), c.Request(), c.GetParam("path"))
+
+		if errors.Is(err, static.ErrNotFound) {
+			return fmt.Errorf("static file %s: %w", c.GetParam("path"), ErrNotFound)
 		}
 
-		stat, _ := f.Stat()
-		maxAge := "31536000"
-		w.Header().Add("ETag", fmt.Sprintf("%x", stat.ModTime().UnixNano()))
-		w.Header().Add("Cache-Control", fmt.Sprintf("max-age=%s", maxAge))
-		w.Header().Add("Content-Length", fmt.Sprintf("%d", stat.Size()))
+		return err
+	}
 
-		http.ServeContent(w, r, f.Name(), stat.ModTime(), f)
-	})
+	p = strings.TrimSuffix(p, "/")
 
-	return baseHandler
+	for _, pattern := range []string{p + "/", p + "/{path:path}"} {
+		a.addRoute(http.MethodGet, pattern, handler, nil, nil, nil)
+	}
+}
+
+// Assets serves the files under the path with fingerprinted names, for example app.3f9a1c2b.css,
+// and makes the asset template function return these names so the files can be cached forever.
+// The files are hashed when the manifest is not set in the config, see static.LoadManifest for build tool manifests.
+func (a *LeopardApp) Assets(p string, fsys fs.FS, config static.Config) (*static.Manifest, error) {
+	if config.Manifest == nil {
+		manifest, err := static.NewManifest(fsys, a.withPrefix(p))
+
+		if err != nil {
+			return nil, err
+		}
+
+		config.Manifest = manifest
+	}
+
+	if resolver, ok := a.TemplateDriver.(drivers.AssetResolver); ok {
+		resolver.SetAssetResolver(config.Manifest.URL)
+	}
+
+	a.StaticFS(p, fsys, config)
+
+	return config.Manifest, nil
+}
+
+// withPrefix prepends the app's prefix to the path.
+func (a *LeopardApp) withPrefix(p string) string {
+	if a.Options == nil || a.Prefix == "" {
+		return p
+	}
+
+	return strings.TrimSuffix(a.Prefix, "/") + p
 }
 
 type RouteGroup struct {
 	prefix     string
 	namePrefix *string
-	middleware []MiddlewareFunc
+	middleware []HandlerFunc
+	flags      []RouteFlag
 	app        *LeopardApp
 }
 
-func (r RouteGroup) GET(p string, h func(r ContextInterface), extras ...any) {
+func (r RouteGroup) GET(p string, h Handler, extras ...any) {
 	r.addRoute(
 		http.MethodGet,
 		p,
@@ -151,7 +261,7 @@ func (r RouteGroup) GET(p string, h func(r ContextInterface), extras ...any) {
 }
 
 // POST register a route with the method POST
-func (r RouteGroup) POST(p string, h func(r ContextInterface), extras ...any) {
+func (r RouteGroup) POST(p string, h Handler, extras ...any) {
 	r.addRoute(
 		http.MethodPost,
 		p,
@@ -161,7 +271,7 @@ func (r RouteGroup) POST(p string, h func(r ContextInterface), extras ...any) {
 }
 
 // PUT register a route with the method PUT
-func (r RouteGroup) PUT(p string, h func(r ContextInterface), extras ...any) {
+func (r RouteGroup) PUT(p string, h Handler, extras ...any) {
 	r.addRoute(
 		http.MethodPut,
 		p,
@@ -171,7 +281,7 @@ func (r RouteGroup) PUT(p string, h func(r ContextInterface), extras ...any) {
 }
 
 // DELETE register a route with the method DELETE
-func (r RouteGroup) DELETE(p string, h func(r ContextInterface), extras ...any) {
+func (r RouteGroup) DELETE(p string, h Handler, extras ...any) {
 	r.addRoute(
 		http.MethodDelete,
 		p,
@@ -181,7 +291,7 @@ func (r RouteGroup) DELETE(p string, h func(r ContextInterface), extras ...any)
 }
 
 // PATCH register a route with the method PATCH
-func (r RouteGroup) PATCH(p string, h func(r ContextInterface), extras ...any) {
+func (r RouteGroup) PATCH(p string, h Handler, extras ...any) {
 	r.addRoute(
 		http.MethodPatch,
 		p,
@@ -198,25 +308,34 @@ func (r RouteGroup) Group(prefix string, groupHandler func(group RouteGroup), ex
 		prefix:     path.Join(r.prefix, prefix),
 		namePrefix: r.addNamePrefix(name),
 		app:        r.app,
-		middleware: middleware,
+		middleware: joinHandlers(r.middleware, middleware),
+		flags:      append(append([]RouteFlag(nil), r.flags...), parseFlags(extras)...),
 	}
 	groupHandler(group)
 
 	return group
 }
 
-func (r RouteGroup) addRoute(method string, p string, h func(r ContextInterface), extras ...any) {
+func (r RouteGroup) addRoute(method string, p string, h Handler, extras ...any) {
 	name, middleware := parseExtras(extras)
 
 	r.app.addRoute(
 		method,
 		path.Join(r.prefix, p),
-		h,
+		toHandlerFunc(h),
 		r.addNamePrefix(name),
-		append(r.middleware, middleware...),
+		joinHandlers(r.middleware, middleware),
+		append(append([]RouteFlag(nil), r.flags...), parseFlags(extras)...),
 	)
 }
 
+// Use adds middleware to the group, it runs for the routes and nested groups registered after it.
+func (r *RouteGroup) Use(middleware ...any) {
+	_, handlers := parseExtras(middleware)
+
+	r.middleware = joinHandlers(r.middleware, handlers)
+}
+
 func (r RouteGroup) addNamePrefix(name *string) *string {
 	if name == nil {
 		return nil
@@ -231,7 +350,7 @@ func (r RouteGroup) addNamePrefix(name *string) *string {
 	return &temp
 }
 
-func parseExtras(extras []any) (name *string, middleware []MiddlewareFunc) {
+func parseExtras(extras []any) (name *string, middleware []HandlerFunc) {
 	for _, e := range extras {
 		switch e.(type) {
 		case string:
@@ -239,14 +358,49 @@ func parseExtras(extras []any) (name *string, middleware []MiddlewareFunc) {
 			name = &temp
 			break
 
-		case MiddlewareFunc:
-			middleware = append(middleware, e.(MiddlewareFunc))
+		case MiddlewareFunc, HandlerFunc, func(ContextInterface), func(ContextInterface) error:
+			middleware = append(middleware, toHandlerFunc(e))
 			break
 
 		case []MiddlewareFunc:
-			middleware = append(middleware, e.([]MiddlewareFunc)...)
+			for _, m := range e.([]MiddlewareFunc) {
+				middleware = append(middleware, toHandlerFunc(m))
+			}
+			break
+
+		case []HandlerFunc:
+			middleware = append(middleware, e.([]HandlerFunc)...)
 			break
 		}
 	}
 	return
 }
+
+// RouteFlag marks a route, middleware can check for it with HasRouteFlag.
+// Flags are passed in the extras of a route or a group, the flags of a group apply to all its routes.
+type RouteFlag string
+
+type routeFlagsKey struct{}
+
+func parseFlags(extras []any) (flags []RouteFlag) {
+	for _, e := range extras {
+		if flag, ok := e.(RouteFlag); ok {
+			flags = append(flags, flag)
+		}
+	}
+
+	return
+}
+
+// HasRouteFlag checks if the matched route of the request has the flag.
+func HasRouteFlag(c ContextInterface, flag RouteFlag) bool {
+	flags, _ := c.Request().Context().Value(routeFlagsKey{}).([]RouteFlag)
+
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
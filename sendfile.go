@@ -0,0 +1,267 @@
+package leopard
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/volix-dev/leopard/files"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InlineFileTypes are the content types that SendFile and StorageDir show in the browser.
+// Types ending with a slash, like video/, include all their subtypes. Other files, like html and svg,
+// could run scripts on the site when they were uploaded by a user, they are sent as application/octet-stream downloads.
+var InlineFileTypes = []string{
+	"text/plain", "text/csv", "application/json", "application/pdf",
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif", "image/bmp", "image/x-icon",
+	"audio/", "video/", "font/",
+}
+
+// SendFile streams a file from the file driver of the app.
+// Range and conditional requests are supported, the Content-Type is detected from the extension or the content.
+// Files that are not one of the InlineFileTypes are sent as downloads.
+func (c *Context) SendFile(path string) error {
+	return serveFile(c, c.a.FileDriver, path, "")
+}
+
+// Download streams a file from the file driver of the app as an attachment,
+// the browser saves it with the filename.
+func (c *Context) Download(path string, filename string) error {
+	return serveFile(c, c.a.FileDriver, path, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+}
+
+// StorageDir serves the files of the driver under the prefix, for example /uploads/avatars/1.png.
+// The route runs the middleware of the app like any other route.
+func (a *LeopardApp) StorageDir(prefix string, driver files.Driver) {
+	handler := func(c ContextInterface) error {
+		return serveFile(c, driver, c.GetParam("path"), "")
+	}
+
+	p := strings.TrimSuffix(prefix, "/") + "/{path:path}"
+
+	a.addRoute(http.MethodGet, p, handler, nil, nil, nil)
+}
+
+// serveFile writes the file of the driver to the response.
+// The path is cleaned so it can not point outside the driver.
+func serveFile(c ContextInterface, driver files.Driver, name string, disposition string) error {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	info, err := driver.Stat(name)
+
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) || name == "" {
+		return fmt.Errorf("file %s: %w", name, ErrNotFound)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	r := c.Request()
+	header := c.Response().Header()
+	etag := fmt.Sprintf(`"%x-%x"`, info.Size(), info.ModTime().UnixNano())
+
+	header.Set("ETag", etag)
+	header.Set("Accept-Ranges", "bytes")
+
+	if !info.ModTime().IsZero() {
+		header.Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	}
+
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	if disposition != "" {
+		header.Set("Content-Disposition", disposition)
+	}
+
+	if notModified(r, etag, info.ModTime()) {
+		header.Del("Content-Type")
+		c.Status(http.StatusNotModified)
+
+		return nil
+	}
+
+	stream, err := driver.ReadFileStream(name)
+
+	if err != nil {
+		return err
+	}
+
+	defer stream.Close()
+
+	var content io.Reader = stream
+	contentType := mime.TypeByExtension(path.Ext(name))
+
+	if contentType == "" {
+		sniff := make([]byte, 512)
+		n, _ := io.ReadFull(stream, sniff)
+		contentType = http.DetectContentType(sniff[:n])
+		content = io.MultiReader(bytes.NewReader(sniff[:n]), stream)
+	}
+
+	if !inlineType(contentType) {
+		contentType = "application/octet-stream"
+
+		if disposition == "" {
+			header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(name)}))
+		}
+	}
+
+	header.Set("Content-Type", contentType)
+
+	start, length, status := int64(0), info.Size(), http.StatusOK
+
+	// Requests for multiple ranges are served completely.
+	rangeHeader := r.Header.Get("Range")
+
+	if rangeHeader != "" && !strings.Contains(rangeHeader, ",") && ifRange(r, etag, info.ModTime()) {
+		var ok bool
+		start, length, ok = parseRange(rangeHeader, info.Size())
+
+		if !ok {
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size()))
+
+			return NewHTTPError(http.StatusRequestedRangeNotSatisfiable, "")
+		}
+
+		status = http.StatusPartialContent
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, info.Size()))
+	}
+
+	header.Set("Content-Length", strconv.FormatInt(length, 10))
+	c.Status(status)
+
+	if r.Method == http.MethodHead {
+		return nil
+	}
+
+	if err := skip(content, start); err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(c.ResponseWriter(), content, length)
+
+	return err
+}
+
+// inlineType checks if the content type is one of the InlineFileTypes.
+func inlineType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	for _, t := range InlineFileTypes {
+		if mediaType == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// skip moves the reader forward, seeking when the reader supports it.
+func skip(r io.Reader, n int64) error {
+	if n == 0 {
+		return nil
+	}
+
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(n, io.SeekStart)
+
+		return err
+	}
+
+	_, err := io.CopyN(io.Discard, r, n)
+
+	return err
+}
+
+// notModified checks the If-None-Match and If-Modified-Since headers.
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+
+	return err == nil && !modTime.IsZero() && !modTime.Truncate(time.Second).After(since)
+}
+
+// ifRange checks if the range should be used, the If-Range header has to match the file when it is set.
+func ifRange(r *http.Request, etag string, modTime time.Time) bool {
+	condition := r.Header.Get("If-Range")
+
+	if condition == "" {
+		return true
+	}
+
+	if strings.HasPrefix(condition, `"`) || strings.HasPrefix(condition, "W/") {
+		return condition == etag
+	}
+
+	date, err := http.ParseTime(condition)
+
+	return err == nil && modTime.Truncate(time.Second).Equal(date)
+}
+
+// parseRange parses a single byte range, like bytes=0-499, bytes=500- or bytes=-500.
+// It returns the start and the length of the range.
+func parseRange(header string, size int64) (int64, int64, bool) {
+	if !strings.HasPrefix(header, "bytes=") {
+		return 0, 0, false
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(strings.TrimPrefix(header, "bytes=")), "-")
+
+	if !found {
+		return 0, 0, false
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+
+		if err != nil || suffix <= 0 {
+			return 0, 0, false
+		}
+
+		if suffix > size {
+			suffix = size
+		}
+
+		return size - suffix, suffix, size > 0
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+
+	end := size - 1
+
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+
+		if end >= size {
+			end = size - 1
+		}
+	}
+
+	return start, end - start + 1, true
+}
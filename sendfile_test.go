@@ -0,0 +1,157 @@
+package leopard_test
+
+import (
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"github.com/volix-dev/leopard/static"
+	"github.com/volix-dev/leopard/templating/drivers/twigDriver"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func sendFileApp(t *testing.T) *leopardtest.App {
+	a := leopardtest.New(t)
+
+	if err := a.FileDriver.WriteFile("docs/report.txt", []byte("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.FileDriver.WriteFile("docs/blob", []byte("%PDF-1.4 report")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.FileDriver.WriteFile("uploads/x.html", []byte("<script>alert(1)</script>")); err != nil {
+		t.Fatal(err)
+	}
+
+	a.GET("/report", func(c leopard.ContextInterface) error {
+		return c.SendFile("docs/report.txt")
+	})
+
+	a.GET("/download", func(c leopard.ContextInterface) error {
+		return c.Download("docs/report.txt", "Q1 report.txt")
+	})
+
+	a.StorageDir("/storage", a.FileDriver)
+
+	return a
+}
+
+func TestSendFile(t *testing.T) {
+	client := sendFileApp(t).Test()
+
+	response := client.GET("/report").Expect(t).
+		Status(200).
+		Header("Content-Type", "text/plain; charset=utf-8").
+		Header("Content-Length", "10").
+		BodyEquals("0123456789")
+
+	client.GET("/download").Expect(t).
+		Status(200).
+		Header("Content-Disposition", `attachment; filename="Q1 report.txt"`)
+
+	client.GET("/storage/docs/blob").Expect(t).
+		Status(200).
+		Header("Content-Type", "application/pdf")
+
+	client.HEAD("/storage/docs/report.txt").Expect(t).
+		Status(200).
+		Header("Content-Length", "10").
+		BodyEquals("")
+
+	client.GET("/storage/docs").Expect(t).Status(404)
+
+	// Files that could run scripts are never shown in the browser.
+	client.GET("/storage/uploads/x.html").Expect(t).
+		Status(200).
+		Header("Content-Type", "application/octet-stream").
+		Header("Content-Disposition", `attachment; filename=x.html`).
+		Header("X-Content-Type-Options", "nosniff")
+
+	etag := response.Response.Header.Get("ETag")
+
+	client.GET("/report").WithHeader("If-None-Match", etag).Expect(t).Status(304).BodyEquals("")
+	client.GET("/report").
+		WithHeader("If-Modified-Since", response.Response.Header.Get("Last-Modified")).
+		Expect(t).
+		Status(304)
+}
+
+func TestSendFileRanges(t *testing.T) {
+	client := sendFileApp(t).Test()
+
+	client.GET("/report").WithHeader("Range", "bytes=2-4").Expect(t).
+		Status(http.StatusPartialContent).
+		Header("Content-Range", "bytes 2-4/10").
+		BodyEquals("234")
+
+	client.GET("/report").WithHeader("Range", "bytes=-3").Expect(t).
+		Status(http.StatusPartialContent).
+		BodyEquals("789")
+
+	client.GET("/report").WithHeader("Range", "bytes=8-").Expect(t).
+		Status(http.StatusPartialContent).
+		BodyEquals("89")
+
+	client.GET("/report").WithHeader("Range", "bytes=20-30").Expect(t).
+		Status(http.StatusRequestedRangeNotSatisfiable).
+		Header("Content-Range", "bytes */10")
+
+	client.GET("/report").WithHeader("Range", "bytes=0-1,4-5").Expect(t).
+		Status(200).
+		BodyEquals("0123456789")
+
+	client.GET("/report").
+		WithHeader("Range", "bytes=0-1").
+		WithHeader("If-Range", `"stale"`).
+		Expect(t).
+		Status(200)
+}
+
+func TestStaticFS(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.StaticFS("/assets", fstest.MapFS{"app.js": {Data: []byte("app()")}}, static.Config{})
+
+	client := a.Test()
+
+	client.GET("/assets/app.js").Expect(t).
+		Status(200).
+		Header("Content-Type", "text/javascript; charset=utf-8").
+		BodyEquals("app()")
+
+	client.GET("/assets/missing.js").Expect(t).Status(404)
+}
+
+func TestAssets(t *testing.T) {
+	templates := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(templates, "page.twig"), []byte(`<link href="{{ asset('app.css') }}">`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := leopardtest.New(t,
+		leopard.WithTemplateDriver(twigDriver.NewTwigDriver()),
+		leopard.WithTemplatePath(templates),
+		leopard.WithPrefix("/app"),
+	)
+
+	manifest, err := a.Assets("/assets", fstest.MapFS{"app.css": {Data: []byte("body{}")}}, static.Config{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.GET("/", func(c leopard.ContextInterface) error {
+		return c.RenderTemplate("page.twig", nil)
+	})
+
+	client := a.Test()
+	url := manifest.URL("app.css")
+
+	client.GET("/app/").Expect(t).Status(200).BodyEquals(`<link href="` + url + `">`)
+	client.GET(url).Expect(t).Status(200).Header("Cache-Control", "public, max-age=31536000, immutable").BodyEquals("body{}")
+}
@@ -0,0 +1,246 @@
+package leopard
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/volix-dev/leopard/helpers"
+	"github.com/volix-dev/leopard/sessions"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SessionConfig configures the Sessions middleware.
+// Zero values are read from the environment.
+type SessionConfig struct {
+	// CookieName is the name of the session cookie, defaults to SESSION_COOKIE.
+	CookieName string
+
+	// Secret signs the session cookie, defaults to SESSION_SECRET.
+	// A random secret is used when it is not set, the sessions then end when the app restarts.
+	Secret string
+
+	// IdleTimeout ends sessions that are not used for this long, defaults to SESSION_IDLE_TIMEOUT.
+	IdleTimeout time.Duration
+
+	// Lifetime ends sessions that are older than this, even when they are used, defaults to SESSION_LIFETIME.
+	Lifetime time.Duration
+
+	// Path is the path of the session cookie, defaults to /.
+	Path string
+
+	// Domain is the domain of the session cookie, it is only sent to the host of the request when it is empty.
+	Domain string
+
+	// Secure only sends the session cookie over https, defaults to SESSION_SECURE.
+	Secure bool
+
+	// SameSite limits sending the session cookie with cross-site requests, defaults to lax.
+	SameSite http.SameSite
+
+	// Store keeps the sessions, defaults to SESSION_STORE. The store "cache" keeps them in the cache of the app,
+	// "cookie" keeps them in an encrypted cookie.
+	Store sessions.Store
+}
+
+// fillFromEnv sets all the zero values to the values from the environment.
+func (c *SessionConfig) fillFromEnv() error {
+	var err error
+
+	if c.CookieName == "" {
+		c.CookieName = EnvSettingD("SESSION_COOKIE", "leopard_session").GetValue().(string)
+	}
+
+	if c.Secret == "" {
+		c.Secret = EnvSettingD("SESSION_SECRET", "").GetValue().(string)
+	}
+
+	if c.IdleTimeout == 0 {
+		if c.IdleTimeout, err = envDuration("SESSION_IDLE_TIMEOUT", "2h"); err != nil {
+			return err
+		}
+	}
+
+	if c.Lifetime == 0 {
+		if c.Lifetime, err = envDuration("SESSION_LIFETIME", "24h"); err != nil {
+			return err
+		}
+	}
+
+	if c.Path == "" {
+		c.Path = "/"
+	}
+
+	if c.SameSite == 0 {
+		c.SameSite = http.SameSiteLaxMode
+	}
+
+	if !c.Secure {
+		if c.Secure, err = envBool("SESSION_SECURE", false); err != nil {
+			return err
+		}
+	}
+
+	if c.Store != nil {
+		return nil
+	}
+
+	switch store := EnvSettingD("SESSION_STORE", "cache").GetValue().(string); store {
+	case "cache":
+		return nil
+
+	case "cookie":
+		if c.Secret == "" {
+			return fmt.Errorf("the cookie session store needs SESSION_SECRET")
+		}
+
+		key := sha256.Sum256([]byte(c.Secret))
+		c.Store, err = sessions.NewCookieStore(key[:])
+
+		return err
+
+	default:
+		return fmt.Errorf("invalid session store %q for SESSION_STORE", store)
+	}
+}
+
+// Sessions loads the session of the request when Context.Session is used,
+// and saves it when it was changed before the response is written.
+// The session cookie holds the ID of the session and is signed, unknown and expired IDs start a new session.
+func Sessions(config SessionConfig) HandlerFunc {
+	if err := config.fillFromEnv(); err != nil {
+		panic("sessions: " + err.Error())
+	}
+
+	secret := newCookieSecret(config.Secret, "sessions end")
+
+	var manager *sessions.Manager
+	var once sync.Once
+
+	return func(c ContextInterface) error {
+		hc, ok := c.(httpContext)
+
+		if !ok {
+			return c.Next()
+		}
+
+		// The default store uses the cache of the app, which is only known once there is a request.
+		once.Do(func() {
+			store := config.Store
+
+			if store == nil {
+				store = sessions.NewCacheStore(c.App().Cache.Driver)
+			}
+
+			manager = sessions.NewManager(store, config.IdleTimeout, config.Lifetime)
+		})
+
+		key := secret.get(c)
+		value := ""
+
+		if cookie, err := c.GetCookie(config.CookieName); err == nil {
+			value, _ = helpers.Unsign(cookie.Value, key)
+		}
+
+		session := manager.Open(value)
+		hc.setRequest(c.Request().WithContext(sessions.NewContext(c.Request().Context(), session)))
+
+		saved := false
+		save := func() error {
+			if saved {
+				// The cookie can not be changed once the headers are written.
+				if session.Modified() {
+					c.Logger().Warning("leopard: the session was changed after the response was written, the changes are lost")
+				}
+
+				return nil
+			}
+
+			saved = true
+			value, write, err := manager.Save(session)
+
+			if err != nil || !write {
+				return err
+			}
+
+			cookie := &http.Cookie{
+				Name:     config.CookieName,
+				Path:     config.Path,
+				Domain:   config.Domain,
+				Secure:   config.Secure,
+				HttpOnly: true,
+				SameSite: config.SameSite,
+			}
+
+			if value == "" {
+				cookie.MaxAge = -1
+			} else {
+				cookie.Value = helpers.Sign(value, key)
+				cookie.Expires = session.Expires()
+			}
+
+			c.SetResponseCookie(cookie)
+
+			return nil
+		}
+
+		// The cookie has to be set before the headers are written, which usually happens in the handler.
+		c.Response().Before(func() {
+			if err := save(); err != nil {
+				c.Logger().Error(fmt.Errorf("saving the session: %w", err))
+			}
+		})
+
+		err := c.Next()
+
+		if saveErr := save(); err == nil {
+			err = saveErr
+		}
+
+		return err
+	}
+}
+
+// Session gets the session of the request, it needs the Sessions middleware.
+func (c *Context) Session() *sessions.Session {
+	session := sessions.FromContext(c.request.Context())
+
+	if session == nil {
+		panic("leopard: Context.Session needs the Sessions middleware")
+	}
+
+	return session
+}
+
+// cookieSecret is the secret a middleware signs its cookies with.
+type cookieSecret struct {
+	value []byte
+	lost  string
+	warn  sync.Once
+}
+
+// newCookieSecret uses the secret, SESSION_SECRET or a random secret, in that order.
+// lost is what ends when the app restarts with a random secret, it is part of the warning.
+func newCookieSecret(secret string, lost string) *cookieSecret {
+	if secret == "" {
+		secret = EnvSettingD("SESSION_SECRET", "").GetValue().(string)
+	}
+
+	if secret != "" {
+		return &cookieSecret{value: []byte(secret)}
+	}
+
+	return &cookieSecret{value: randomBytes(32), lost: lost}
+}
+
+// get gets the secret. The warning about a random secret is logged on the first request,
+// with the logger of the app that is only known once there is a request.
+func (s *cookieSecret) get(c ContextInterface) []byte {
+	if s.lost != "" {
+		s.warn.Do(func() {
+			c.App().logger().Warning("SESSION_SECRET is not set, " + s.lost + " when the app restarts.")
+		})
+	}
+
+	return s.value
+}
@@ -0,0 +1,190 @@
+package leopard_test
+
+import (
+	"bytes"
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/defaultlogger"
+	"github.com/volix-dev/leopard/leopardtest"
+	"strings"
+	"testing"
+)
+
+func sessionApp(t *testing.T, config leopard.SessionConfig) *leopardtest.App {
+	a := leopardtest.New(t)
+
+	a.Use(leopard.Sessions(config))
+
+	a.GET("/visit", func(c leopard.ContextInterface) error {
+		var visits int
+
+		if _, err := c.Session().Get("visits", &visits); err != nil {
+			return err
+		}
+
+		if err := c.Session().Set("visits", visits+1); err != nil {
+			return err
+		}
+
+		_, err := c.WriteStringF("%d", visits+1)
+
+		return err
+	})
+
+	a.GET("/id", func(c leopard.ContextInterface) error {
+		_, err := c.WriteString(c.Session().ID())
+
+		return err
+	})
+
+	a.GET("/nothing", func(c leopard.ContextInterface) {})
+
+	a.POST("/login", func(c leopard.ContextInterface) error {
+		return c.Session().Regenerate()
+	})
+
+	a.POST("/logout", func(c leopard.ContextInterface) error {
+		return c.Session().Destroy()
+	})
+
+	return a
+}
+
+func TestSessions(t *testing.T) {
+	a := sessionApp(t, leopard.SessionConfig{Secret: "secret"})
+	client := a.Test()
+
+	response := client.GET("/nothing").Expect(t).Status(200)
+
+	if len(response.Cookies()) != 0 {
+		t.Errorf("expected no session cookie for a request that does not use the session, got %v", response.Cookies())
+	}
+
+	client.GET("/visit").Expect(t).BodyEquals("1")
+	client.GET("/visit").Expect(t).BodyEquals("2")
+
+	id := client.GET("/id").Expect(t).BodyString()
+	cookie := client.Cookies("/")[0]
+
+	if !strings.HasPrefix(cookie.Value, id+".") {
+		t.Errorf("expected the cookie to hold the signed session ID %q, got %q", id, cookie.Value)
+	}
+
+	// A changed signature is not trusted, the client gets a new session.
+	a.Test().GET("/visit").WithCookie("leopard_session", id+".forged").Expect(t).BodyEquals("1")
+
+	client.POST("/login").Expect(t).Status(200)
+
+	if newID := client.GET("/id").Expect(t).BodyString(); newID == id {
+		t.Errorf("expected a new session ID after regenerating")
+	}
+
+	client.GET("/visit").Expect(t).BodyEquals("3")
+
+	// The old ID does not work anymore.
+	a.Test().GET("/visit").WithCookie("leopard_session", cookie.Value).Expect(t).BodyEquals("1")
+
+	client.POST("/logout").Expect(t).Status(200)
+
+	if cookies := client.Cookies("/"); len(cookies) != 0 {
+		t.Errorf("expected the session cookie to be removed, got %v", cookies)
+	}
+
+	client.GET("/visit").Expect(t).BodyEquals("1")
+}
+
+func TestCookieSessions(t *testing.T) {
+	t.Setenv("SESSION_STORE", "cookie")
+
+	a := sessionApp(t, leopard.SessionConfig{Secret: "secret", CookieName: "state"})
+	client := a.Test()
+
+	client.GET("/visit").Expect(t).BodyEquals("1")
+	client.GET("/visit").Expect(t).BodyEquals("2")
+
+	cookies := client.Cookies("/")
+
+	if len(cookies) != 1 || cookies[0].Name != "state" || strings.Contains(cookies[0].Value, "visits") {
+		t.Errorf("expected an encrypted state cookie, got %v", cookies)
+	}
+
+	client.POST("/logout").Expect(t).Status(200)
+	client.GET("/visit").Expect(t).BodyEquals("1")
+}
+
+func TestSessionWithoutMiddleware(t *testing.T) {
+	a := leopardtest.New(t)
+
+	a.GET("/", func(c leopard.ContextInterface) {
+		c.Session()
+	})
+
+	a.Test().GET("/").Expect(t).Status(500)
+}
+
+func TestSessionChangedAfterWrite(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger := defaultlogger.New()
+	logger.SetOutput(output)
+
+	a := leopardtest.New(t, leopard.WithLogger(logger))
+
+	a.Use(leopard.Sessions(leopard.SessionConfig{Secret: "secret"}))
+
+	a.GET("/late", func(c leopard.ContextInterface) error {
+		if _, err := c.WriteString("written"); err != nil {
+			return err
+		}
+
+		return c.Session().Set("user", 1)
+	})
+
+	client := a.Test()
+	client.GET("/late").Expect(t).Status(200)
+
+	if len(client.Cookies("/")) != 0 {
+		t.Error("expected no session cookie for changes after the response was written")
+	}
+
+	if !strings.Contains(output.String(), "the session was changed after the response was written") {
+		t.Errorf("expected a warning about the lost changes, got %q", output.String())
+	}
+}
+
+func TestSessionsWithoutSecret(t *testing.T) {
+	t.Setenv("SESSION_SECRET", "")
+
+	output := &bytes.Buffer{}
+	logger := defaultlogger.New()
+	logger.SetOutput(output)
+
+	a := leopardtest.New(t, leopard.WithLogger(logger))
+
+	a.Use(leopard.Sessions(leopard.SessionConfig{}), leopard.Flashes(leopard.FlashConfig{Cookie: true}), leopard.CSRF(leopard.CSRFConfig{Cookie: true}))
+
+	a.GET("/visit", func(c leopard.ContextInterface) error {
+		var visits int
+
+		if _, err := c.Session().Get("visits", &visits); err != nil {
+			return err
+		}
+
+		if err := c.Session().Set("visits", visits+1); err != nil {
+			return err
+		}
+
+		_, err := c.WriteStringF("%d", visits+1)
+
+		return err
+	})
+
+	client := a.Test()
+	client.GET("/visit").Expect(t).BodyEquals("1")
+	client.GET("/visit").Expect(t).BodyEquals("2")
+
+	// The warnings go to the logger of the app, once for every middleware.
+	for _, warning := range []string{"sessions end", "flash messages are lost", "CSRF tokens end"} {
+		if count := strings.Count(output.String(), "SESSION_SECRET is not set, "+warning); count != 1 {
+			t.Errorf("expected one warning that %s, got %q", warning, output.String())
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package sessions
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// touchInterval is how often the activity of a session that did not change is saved.
+// Saving it on every request would write to the store for every request.
+const touchInterval = time.Minute
+
+// Manager opens and saves the sessions of requests.
+type Manager struct {
+	store       Store
+	idleTimeout time.Duration
+	lifetime    time.Duration
+	now         func() time.Time
+}
+
+// NewManager creates a manager for the sessions in the store.
+// Sessions end when they are not used for the idle timeout, or when they are older than the lifetime.
+func NewManager(store Store, idleTimeout time.Duration, lifetime time.Duration) *Manager {
+	return &Manager{
+		store:       store,
+		idleTimeout: idleTimeout,
+		lifetime:    lifetime,
+		now:         time.Now,
+	}
+}
+
+// Open returns the session for the value of the session cookie, an empty value starts a new session.
+// The session is not loaded from the store until it is used.
+func (m *Manager) Open(value string) *Session {
+	return &Session{
+		manager: m,
+		value:   value,
+	}
+}
+
+// Save stores the session when it was changed, or when its activity has to be recorded.
+// It returns the value for the session cookie and if the cookie has to be written,
+// an empty value means the cookie has to be removed.
+// Sessions that were never used, or new sessions without values, are not saved.
+func (m *Manager) Save(s *Session) (string, bool, error) {
+	if s.record == nil {
+		return "", false, nil
+	}
+
+	for _, id := range s.previousIDs {
+		if err := m.store.Delete(id); err != nil {
+			return "", false, err
+		}
+	}
+
+	removed := len(s.previousIDs) > 0
+	s.previousIDs = nil
+
+	now := m.now()
+	touch := s.value != "" && !removed && now.Sub(s.record.Active) >= touchInterval
+
+	if !s.dirty && !touch {
+		return "", removed, nil
+	}
+
+	ttl := m.idleTimeout
+
+	if remaining := s.record.Created.Add(m.lifetime).Sub(now); remaining < ttl {
+		ttl = remaining
+	}
+
+	if ttl <= 0 {
+		return "", true, m.store.Delete(s.record.ID)
+	}
+
+	s.record.Active = now
+
+	value, err := m.store.Save(s.record, ttl)
+
+	if err != nil {
+		return "", false, err
+	}
+
+	s.value = value
+	s.dirty = false
+	s.destroyed = false
+
+	return value, true, nil
+}
+
+// expired checks the idle timeout and the lifetime of the record.
+func (m *Manager) expired(record *Record) bool {
+	now := m.now()
+
+	return now.Sub(record.Active) > m.idleTimeout || now.Sub(record.Created) > m.lifetime
+}
+
+func (m *Manager) newRecord() *Record {
+	now := m.now()
+
+	return &Record{
+		ID:      newID(),
+		Values:  map[string]json.RawMessage{},
+		Created: now,
+		Active:  now,
+	}
+}
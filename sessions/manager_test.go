@@ -0,0 +1,117 @@
+package sessions
+
+import (
+	"github.com/volix-dev/leopard/caching"
+	_ "github.com/volix-dev/leopard/caching/drivers"
+	"testing"
+	"time"
+)
+
+func TestExpiry(t *testing.T) {
+	driver, err := caching.New("memory", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	m := NewManager(NewCacheStore(driver), time.Hour, 3*time.Hour)
+	m.now = func() time.Time {
+		return now
+	}
+
+	session := m.Open("")
+
+	if err := session.Set("user", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	value, write, err := m.Save(session)
+
+	if err != nil || !write || value != session.ID() {
+		t.Fatalf("expected the new session to be saved, got %q %v %v", value, write, err)
+	}
+
+	// Reading the session records the activity, at most once a minute.
+	now = now.Add(50 * time.Minute)
+	session = m.Open(value)
+
+	if !session.Has("user") {
+		t.Fatal("expected the session to be loaded")
+	}
+
+	if _, write, _ := m.Save(session); !write {
+		t.Error("expected the activity to be saved")
+	}
+
+	if _, write, _ := m.Save(m.Open(value)); write {
+		t.Error("expected a session that was not used not to be saved")
+	}
+
+	now = now.Add(50 * time.Minute)
+
+	if !m.Open(value).Has("user") {
+		t.Fatal("expected the session to be kept alive by the activity")
+	}
+
+	// The idle timeout passed.
+	now = now.Add(61 * time.Minute)
+
+	if m.Open(value).Has("user") {
+		t.Error("expected the idle session to be expired")
+	}
+
+	// The lifetime passes even when the session is used or regenerated.
+	now = time.Now()
+	session = m.Open("")
+	_ = session.Set("user", 1)
+	value, _, _ = m.Save(session)
+
+	for i := 0; i < 4; i++ {
+		now = now.Add(50 * time.Minute)
+		session = m.Open(value)
+		session.Has("user")
+
+		if i%2 == 0 {
+			_ = session.Regenerate()
+		}
+
+		value, _, _ = m.Save(session)
+	}
+
+	if m.Open(value).Has("user") {
+		t.Error("expected the session to expire after its lifetime")
+	}
+}
+
+func TestCookieStore(t *testing.T) {
+	store, err := NewCookieStore(make([]byte, 32))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager(store, time.Hour, time.Hour)
+	session := m.Open("")
+	_ = session.Set("name", "bob")
+
+	value, _, err := m.Save(session)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := m.Open(value).GetString("name"); name != "bob" {
+		t.Errorf("expected the value from the cookie, got %q", name)
+	}
+
+	if m.Open(value[:len(value)-2] + "xx").Has("name") {
+		t.Error("expected a changed cookie to be ignored")
+	}
+
+	_ = session.Set("data", make([]byte, 4096))
+
+	if _, _, err := m.Save(session); err != ErrCookieTooLarge {
+		t.Errorf("expected ErrCookieTooLarge, got %v", err)
+	}
+}
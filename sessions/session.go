@@ -0,0 +1,218 @@
+// Package sessions keeps data of a client between requests.
+// The client gets a cookie with the ID of the session, the data is kept in a Store.
+package sessions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Record is the data of a session as it is kept by a Store.
+type Record struct {
+	ID      string                     `json:"id"`
+	Values  map[string]json.RawMessage `json:"values"`
+	Created time.Time                  `json:"created"`
+	Active  time.Time                  `json:"active"`
+}
+
+// Session is the session of a request, it is loaded from the store the first time it is used.
+// Values are stored as json, they can be read back into any type that has the same json representation.
+type Session struct {
+	manager *Manager
+	value   string
+	record  *Record
+	err     error
+
+	dirty       bool
+	destroyed   bool
+	previousIDs []string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of the context that carries the session.
+func NewContext(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, contextKey{}, session)
+}
+
+// FromContext gets the session of the context, it is nil when there is none.
+func FromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(contextKey{}).(*Session)
+
+	return session
+}
+
+// load reads the record from the store on first use, a new session is started when it is missing or expired.
+func (s *Session) load() error {
+	if s.record != nil || s.err != nil {
+		return s.err
+	}
+
+	if s.value != "" {
+		record, ok, err := s.manager.store.Load(s.value)
+
+		if err != nil {
+			s.err = err
+
+			return err
+		}
+
+		if ok && !s.manager.expired(record) {
+			s.record = record
+
+			return nil
+		}
+
+		if ok {
+			s.previousIDs = append(s.previousIDs, record.ID)
+		}
+	}
+
+	s.record = s.manager.newRecord()
+
+	return nil
+}
+
+// ID gets the ID of the session, it changes when the session is regenerated.
+func (s *Session) ID() string {
+	if s.load() != nil {
+		return ""
+	}
+
+	return s.record.ID
+}
+
+// Get reads the value of the key into the target pointer, it returns false when the key is not set.
+func (s *Session) Get(key string, target any) (bool, error) {
+	if err := s.load(); err != nil {
+		return false, err
+	}
+
+	data, ok := s.record.Values[key]
+
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, target)
+}
+
+// GetString gets the value of the key as a string, it is empty when the key is not set or not a string.
+func (s *Session) GetString(key string) string {
+	var value string
+
+	_, _ = s.Get(key, &value)
+
+	return value
+}
+
+// Has checks if the key is set.
+func (s *Session) Has(key string) bool {
+	if s.load() != nil {
+		return false
+	}
+
+	_, ok := s.record.Values[key]
+
+	return ok
+}
+
+// Set sets the value of the key, the value must be serializable to json.
+func (s *Session) Set(key string, value any) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	s.record.Values[key] = data
+	s.dirty = true
+
+	return nil
+}
+
+// Pull reads the value of the key into the target pointer and deletes it.
+func (s *Session) Pull(key string, target any) (bool, error) {
+	ok, err := s.Get(key, target)
+
+	if ok {
+		s.Delete(key)
+	}
+
+	return ok, err
+}
+
+// Delete removes the key from the session.
+func (s *Session) Delete(key string) {
+	if s.load() != nil {
+		return
+	}
+
+	if _, ok := s.record.Values[key]; ok {
+		delete(s.record.Values, key)
+		s.dirty = true
+	}
+}
+
+// Regenerate gives the session a new ID and keeps its values, the old ID stops working.
+// Call it when the privileges of the user change, like on login, so an ID that was planted
+// in the browser of the user by an attacker (session fixation) is worthless.
+// The session keeps its creation time, so regenerating it does not extend its lifetime.
+func (s *Session) Regenerate() error {
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	s.previousIDs = append(s.previousIDs, s.record.ID)
+	s.record.ID = newID()
+	s.dirty = true
+	s.destroyed = false
+
+	return nil
+}
+
+// Destroy removes the session and all its values, values set afterwards start a new session.
+func (s *Session) Destroy() error {
+	if err := s.load(); err != nil {
+		return err
+	}
+
+	s.previousIDs = append(s.previousIDs, s.record.ID)
+	s.record = s.manager.newRecord()
+	s.dirty = false
+	s.destroyed = true
+
+	return nil
+}
+
+// Modified checks if the session has changes that were not saved yet.
+func (s *Session) Modified() bool {
+	return s.dirty || len(s.previousIDs) > 0
+}
+
+// Expires gets the time the session ends, regardless of activity.
+func (s *Session) Expires() time.Time {
+	if s.load() != nil {
+		return time.Time{}
+	}
+
+	return s.record.Created.Add(s.manager.lifetime)
+}
+
+// newID generates a random session ID of 256 bits.
+func newID() string {
+	data := make([]byte, 32)
+
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
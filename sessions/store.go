@@ -0,0 +1,158 @@
+package sessions
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/volix-dev/leopard/caching"
+	"math"
+	"time"
+)
+
+// Store keeps the records of sessions.
+type Store interface {
+	// Load gets the record for the value of the session cookie, it returns false when there is none.
+	Load(value string) (*Record, bool, error)
+
+	// Save stores the record until the ttl passes and returns the value for the session cookie.
+	Save(record *Record, ttl time.Duration) (string, error)
+
+	// Delete removes the record with the ID.
+	Delete(id string) error
+}
+
+// CacheStore keeps the records in a caching driver, like the memory or redis driver.
+// The session cookie only holds the ID of the session.
+type CacheStore struct {
+	driver caching.Driver
+	prefix string
+}
+
+// NewCacheStore creates a store that keeps the records in the driver, under keys that start with session:.
+func NewCacheStore(driver caching.Driver) *CacheStore {
+	return &CacheStore{
+		driver: driver,
+		prefix: "session:",
+	}
+}
+
+func (s *CacheStore) Load(value string) (*Record, bool, error) {
+	var data []byte
+
+	ok, err := s.driver.Get(s.prefix+value, &data)
+
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	record := &Record{}
+
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, false, err
+	}
+
+	return record, record.ID == value, nil
+}
+
+func (s *CacheStore) Save(record *Record, ttl time.Duration) (string, error) {
+	data, err := json.Marshal(record)
+
+	if err != nil {
+		return "", err
+	}
+
+	seconds := int(math.Ceil(ttl.Seconds()))
+
+	if err := s.driver.SetTTL(s.prefix+record.ID, data, seconds); err != nil {
+		return "", err
+	}
+
+	return record.ID, nil
+}
+
+func (s *CacheStore) Delete(id string) error {
+	return s.driver.Delete(s.prefix + id)
+}
+
+// ErrCookieTooLarge is returned by the CookieStore when a session does not fit in a cookie.
+var ErrCookieTooLarge = errors.New("sessions: the session is too large for a cookie")
+
+// maxCookieSize leaves room for the name and the attributes of the cookie, browsers allow 4096 bytes.
+const maxCookieSize = 3800
+
+// CookieStore keeps the whole record in the session cookie, encrypted with AES-GCM.
+// It needs no storage on the server, but a session can not be ended before it expires:
+// a copy of an old cookie stays valid until its lifetime has passed.
+type CookieStore struct {
+	aead cipher.AEAD
+}
+
+// NewCookieStore creates a store that encrypts the cookies with the key, it must be 16, 24 or 32 bytes long.
+func NewCookieStore(key []byte) (*CookieStore, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CookieStore{aead: aead}, nil
+}
+
+func (s *CookieStore) Load(value string) (*Record, bool, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return nil, false, nil
+	}
+
+	nonce, sealed := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, sealed, nil)
+
+	// Cookies that were changed or encrypted with another key are ignored, like missing sessions.
+	if err != nil {
+		return nil, false, nil
+	}
+
+	record := &Record{}
+
+	if err := json.Unmarshal(plain, record); err != nil {
+		return nil, false, nil
+	}
+
+	return record, true, nil
+}
+
+func (s *CookieStore) Save(record *Record, ttl time.Duration) (string, error) {
+	plain, err := json.Marshal(record)
+
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plain, nil))
+
+	if len(value) > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+
+	return value, nil
+}
+
+func (s *CookieStore) Delete(id string) error {
+	return nil
+}
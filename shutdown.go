@@ -0,0 +1,86 @@
+package leopard
+
+import (
+	"context"
+	"io"
+)
+
+// ShutdownHook is a function that gets called when the app is shutting down.
+// The provided context gets cancelled when the shutdown timeout is reached.
+type ShutdownHook func(ctx context.Context) error
+
+// OnShutdown registers a hook that gets called when the app is shutting down.
+// Hooks are called in reverse order of registration, after the server stopped accepting requests.
+func (a *LeopardApp) OnShutdown(hook ShutdownHook) {
+	a.shutdownLock.Lock()
+	defer a.shutdownLock.Unlock()
+
+	a.shutdownHooks = append(a.shutdownHooks, hook)
+}
+
+// RegisterCloser registers a resource that gets closed when the app is shutting down.
+// Resources are closed in reverse order of registration.
+func (a *LeopardApp) RegisterCloser(closer io.Closer) {
+	a.OnShutdown(func(ctx context.Context) error {
+		return closer.Close()
+	})
+}
+
+// Shutdown gracefully shuts down the app.
+// It stops accepting new connections, waits for in-flight requests to finish
+// and then closes all registered resources.
+// If the context expires before the server is drained, the remaining connections are closed forcefully.
+// The first error encountered is returned, but all resources are always closed.
+func (a *LeopardApp) Shutdown(ctx context.Context) error {
+	var err error
+
+	for _, server := range a.servers() {
+		if shutdownErr := server.Shutdown(ctx); shutdownErr != nil {
+			_ = server.Close()
+
+			if err == nil {
+				err = shutdownErr
+			}
+		}
+	}
+
+	closeErr := a.closeResources(ctx)
+
+	if err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// servers gets all the servers that were started.
+func (a *LeopardApp) servers() []*SimpleServer {
+	var servers []*SimpleServer
+
+	for _, server := range []*SimpleServer{a.server, a.redirectServer} {
+		if server != nil {
+			servers = append(servers, server)
+		}
+	}
+
+	return servers
+}
+
+// closeResources runs all the shutdown hooks in reverse order.
+// It only runs once, calling it again returns the first error of the first call.
+func (a *LeopardApp) closeResources(ctx context.Context) error {
+	a.closeOnce.Do(func() {
+		a.shutdownLock.Lock()
+		hooks := a.shutdownHooks
+		a.shutdownHooks = nil
+		a.shutdownLock.Unlock()
+
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := hooks[i](ctx); err != nil && a.closeErr == nil {
+				a.closeErr = err
+			}
+		}
+	})
+
+	return a.closeErr
+}
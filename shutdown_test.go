@@ -0,0 +1,55 @@
+package leopard
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestShutdownHooksRunInReverseOrder(t *testing.T) {
+	a := &LeopardApp{}
+
+	var order []int
+
+	for i := 0; i < 3; i++ {
+		i := i
+		a.OnShutdown(func(ctx context.Context) error {
+			order = append(order, i)
+			return nil
+		})
+	}
+
+	if err := a.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(order) != 3 || order[0] != 2 || order[1] != 1 || order[2] != 0 {
+		t.Fatalf("expected hooks to run in reverse order, got %v", order)
+	}
+}
+
+func TestShutdownRunsAllHooksOnError(t *testing.T) {
+	a := &LeopardApp{}
+	expected := errors.New("failed")
+	ran := false
+
+	a.OnShutdown(func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+	a.OnShutdown(func(ctx context.Context) error {
+		return expected
+	})
+
+	if err := a.Close(); err != expected {
+		t.Fatalf("expected %v, got %v", expected, err)
+	}
+
+	if !ran {
+		t.Fatal("expected all hooks to run")
+	}
+
+	if err := a.Close(); err != expected {
+		t.Fatalf("expected closing twice to return the first result, got %v", err)
+	}
+}
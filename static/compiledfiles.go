@@ -2,5 +2,7 @@ package static
 
 import "embed"
 
-// go:embed ../embedded/*
+// FS contains the files leopard needs at runtime, like the error page template.
+//
+//go:embed embedded
 var FS embed.FS
@@ -0,0 +1,176 @@
+package static
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Manifest maps the names of assets to their fingerprinted names, for example app.css to app.3f9a1c2b.css.
+// Fingerprinted files can be cached forever, a new version of a file gets a new name.
+type Manifest struct {
+	prefix  string
+	assets  map[string]string
+	reverse map[string]string
+}
+
+// NewManifest fingerprints all the files of the file system with a hash of their content.
+// The prefix is the url the files are served under, for example /assets.
+// Hidden files and precompressed variants are skipped, the variants are served with their original.
+func NewManifest(fsys fs.FS, prefix string) (*Manifest, error) {
+	m := newManifest(prefix)
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(entry.Name(), ".") && name != "." {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.IsDir() || isVariant(name) {
+			return nil
+		}
+
+		hash, err := hashFile(fsys, name)
+
+		if err != nil {
+			return err
+		}
+
+		ext := path.Ext(name)
+		m.add(name, strings.TrimSuffix(name, ext)+"."+hash+ext)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// LoadManifest reads a manifest json file, like the ones of Vite or the webpack manifest plugin.
+// Both a flat object of names and fingerprinted names, and the Vite format with a file per entry are supported.
+// The files of these manifests are fingerprinted by the build tool, they are served by their fingerprinted name.
+func LoadManifest(fsys fs.FS, file string, prefix string) (*Manifest, error) {
+	data, err := fs.ReadFile(fsys, file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", file, err)
+	}
+
+	m := newManifest(prefix)
+
+	for name, raw := range entries {
+		var fingerprinted string
+
+		if err := json.Unmarshal(raw, &fingerprinted); err != nil {
+			var entry struct {
+				File string `json:"file"`
+			}
+
+			if err := json.Unmarshal(raw, &entry); err != nil || entry.File == "" {
+				return nil, fmt.Errorf("invalid manifest entry %q in %s", name, file)
+			}
+
+			fingerprinted = entry.File
+		}
+
+		m.add(strings.TrimPrefix(name, "/"), strings.TrimPrefix(fingerprinted, "/"))
+	}
+
+	return m, nil
+}
+
+func newManifest(prefix string) *Manifest {
+	return &Manifest{
+		prefix:  strings.TrimSuffix(prefix, "/"),
+		assets:  map[string]string{},
+		reverse: map[string]string{},
+	}
+}
+
+func (m *Manifest) add(name string, fingerprinted string) {
+	m.assets[name] = fingerprinted
+	m.reverse[fingerprinted] = name
+}
+
+// URL gets the url of the asset, the name is used as is when it is not in the manifest.
+func (m *Manifest) URL(name string) string {
+	name = strings.TrimPrefix(name, "/")
+
+	if fingerprinted, ok := m.assets[name]; ok {
+		name = fingerprinted
+	}
+
+	return m.prefix + "/" + name
+}
+
+// Resolve gets the name of the asset for a fingerprinted name.
+func (m *Manifest) Resolve(fingerprinted string) (string, bool) {
+	name, ok := m.reverse[fingerprinted]
+
+	return name, ok
+}
+
+// WriteTo writes the manifest as a flat json object, so it can be generated at build time
+// and read with LoadManifest, for example from an embed.FS.
+func (m *Manifest) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(m.assets, "", "  ")
+
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(append(data, '\n'))
+
+	return int64(n), err
+}
+
+// hashFile gets the first 8 hexadecimal characters of the sha256 hash of the file.
+func hashFile(fsys fs.FS, name string) (string, error) {
+	file, err := fsys.Open(name)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))[:8], nil
+}
+
+// isVariant checks if the file is a precompressed variant of another file.
+func isVariant(name string) bool {
+	for _, encoding := range encodings {
+		if strings.HasSuffix(name, encoding.extension) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,91 @@
+package static_test
+
+import (
+	"bytes"
+	"github.com/volix-dev/leopard/static"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestManifest(t *testing.T) {
+	manifest, err := static.NewManifest(files, "/assets/")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url := manifest.URL("css/app.css")
+
+	if !strings.HasPrefix(url, "/assets/css/app.") || !strings.HasSuffix(url, ".css") || len(url) != len("/assets/css/app.12345678.css") {
+		t.Errorf("expected a fingerprinted url, got %s", url)
+	}
+
+	if manifest.URL("missing.js") != "/assets/missing.js" {
+		t.Errorf("expected unknown assets to keep their name, got %s", manifest.URL("missing.js"))
+	}
+
+	if _, ok := manifest.Resolve("css/app.css.br"); ok {
+		t.Error("expected precompressed variants to be skipped")
+	}
+
+	if _, ok := manifest.Resolve(".env"); ok {
+		t.Error("expected hidden files to be skipped")
+	}
+
+	var saved bytes.Buffer
+
+	if _, err := manifest.WriteTo(&saved); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := static.LoadManifest(fstest.MapFS{"manifest.json": {Data: saved.Bytes()}}, "manifest.json", "/assets")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.URL("css/app.css") != url {
+		t.Errorf("expected the saved manifest to give %s, got %s", url, loaded.URL("css/app.css"))
+	}
+}
+
+func TestViteManifest(t *testing.T) {
+	vite := `{
+		"src/main.ts": {"file": "assets/main.4889e940.js", "src": "src/main.ts", "isEntry": true},
+		"logo.svg": "assets/logo.1a2b3c4d.svg"
+	}`
+
+	manifest, err := static.LoadManifest(fstest.MapFS{"manifest.json": {Data: []byte(vite)}}, "manifest.json", "/build")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.URL("src/main.ts") != "/build/assets/main.4889e940.js" || manifest.URL("logo.svg") != "/build/assets/logo.1a2b3c4d.svg" {
+		t.Errorf("unexpected urls %s and %s", manifest.URL("src/main.ts"), manifest.URL("logo.svg"))
+	}
+
+	if _, err := static.LoadManifest(fstest.MapFS{"manifest.json": {Data: []byte(`{"a": 1}`)}}, "manifest.json", ""); err == nil {
+		t.Error("expected an error for an invalid entry")
+	}
+}
+
+func TestServeFingerprinted(t *testing.T) {
+	manifest, _ := static.NewManifest(files, "")
+	server := static.NewServer(files, static.Config{Manifest: manifest})
+
+	response := serve(server, "GET", manifest.URL("css/app.css"))
+
+	if response.Code != 200 || response.Body.String() != "body{}" {
+		t.Fatalf("expected the original file, got %d %q", response.Code, response.Body.String())
+	}
+
+	if response.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Errorf("expected fingerprinted files to be immutable, got %q", response.Header().Get("Cache-Control"))
+	}
+
+	if response := serve(server, "GET", "/css/app.css"); response.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("expected the original name to be revalidated, got %q", response.Header().Get("Cache-Control"))
+	}
+}
@@ -0,0 +1,365 @@
+package static
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Config configures a Server.
+type Config struct {
+	// Index is the file served for directories, defaults to index.html.
+	Index string
+
+	// Browse lists the contents of directories that have no index file.
+	Browse bool
+
+	// SPA serves the index file of the root for paths that do not exist and have no extension,
+	// so the client side router of a single page application can handle them.
+	SPA bool
+
+	// Precompressed serves the .br and .gz variants of files to clients that accept them.
+	Precompressed bool
+
+	// CacheControl is the Cache-Control header per extension, for example ".css": "public, max-age=31536000".
+	CacheControl map[string]string
+
+	// DefaultCacheControl is the Cache-Control header of the other files, defaults to no-cache.
+	// Browsers still cache the files but check the ETag before using them.
+	DefaultCacheControl string
+
+	// Manifest maps fingerprinted names back to the files they were generated for.
+	// Fingerprinted files are cached for a year, they never change.
+	Manifest *Manifest
+}
+
+// immutable is the Cache-Control header of fingerprinted files.
+const immutable = "public, max-age=31536000, immutable"
+
+// Server serves the files of an fs.FS, like an embed.FS or os.DirFS.
+// Hidden files, which start with a dot, are never served, except for the .well-known directory
+// of RFC 8615 that is used for ACME challenges and JSON Web Key Sets.
+type Server struct {
+	fsys   fs.FS
+	config Config
+	etags  sync.Map
+}
+
+// ErrNotFound is returned by Serve when there is no file to serve.
+var ErrNotFound = fs.ErrNotExist
+
+// encodings are the precompressed variants, in order of preference.
+var encodings = []struct {
+	name      string
+	extension string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// NewServer creates a server for the files.
+func NewServer(fsys fs.FS, config Config) *Server {
+	if config.Index == "" {
+		config.Index = "index.html"
+	}
+
+	if config.DefaultCacheControl == "" {
+		config.DefaultCacheControl = "no-cache"
+	}
+
+	return &Server{
+		fsys:   fsys,
+		config: config,
+	}
+}
+
+// ServeHTTP serves the file at the path of the request.
+// Use http.StripPrefix when the server is mounted under a prefix.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := s.Serve(w, r, r.URL.Path)
+
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+	} else if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// Serve writes the file with the name to the response.
+// It returns an error that matches ErrNotFound when there is nothing to serve, the response is not written then.
+func (s *Server) Serve(w http.ResponseWriter, r *http.Request, name string) error {
+	name, ok := resolve(name)
+
+	if !ok {
+		return ErrNotFound
+	}
+
+	fingerprinted := false
+
+	if s.config.Manifest != nil {
+		if original, ok := s.config.Manifest.Resolve(name); ok {
+			fingerprinted = true
+
+			// Build tools write the fingerprinted files themselves, only names generated by NewManifest are mapped back.
+			if _, err := fs.Stat(s.fsys, name); err != nil {
+				name = original
+			}
+		}
+	}
+
+	info, err := fs.Stat(s.fsys, name)
+
+	if errors.Is(err, fs.ErrNotExist) && s.config.SPA && path.Ext(name) == "" {
+		name = s.config.Index
+		info, err = fs.Stat(s.fsys, name)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		if fingerprinted {
+			w.Header().Set("Cache-Control", immutable)
+		}
+
+		return s.serveFile(w, r, name, info)
+	}
+
+	// Relative links in the index or listing only work with a trailing slash.
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+
+		return nil
+	}
+
+	index := path.Join(name, s.config.Index)
+
+	if indexInfo, err := fs.Stat(s.fsys, index); err == nil && !indexInfo.IsDir() {
+		return s.serveFile(w, r, index, indexInfo)
+	}
+
+	if s.config.Browse {
+		return s.list(w, r, name)
+	}
+
+	return ErrNotFound
+}
+
+// resolve cleans the path and checks that it stays inside the file system and has no hidden parts.
+func resolve(name string) (string, bool) {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+
+	if name == "" {
+		return ".", true
+	}
+
+	if !fs.ValidPath(name) {
+		return "", false
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if hidden(part) {
+			return "", false
+		}
+	}
+
+	return name, true
+}
+
+// hidden checks if a file or directory is hidden, .well-known is not.
+func hidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != ".well-known"
+}
+
+// serveFile writes the file, or one of its precompressed variants.
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, name string, info fs.FileInfo) error {
+	header := w.Header()
+	contentType := mime.TypeByExtension(path.Ext(name))
+	served := name
+
+	if s.config.Precompressed {
+		header.Add("Vary", "Accept-Encoding")
+
+		for _, encoding := range encodings {
+			if !acceptsEncoding(r.Header.Get("Accept-Encoding"), encoding.name) {
+				continue
+			}
+
+			variant, err := fs.Stat(s.fsys, name+encoding.extension)
+
+			if err == nil && !variant.IsDir() {
+				header.Set("Content-Encoding", encoding.name)
+				served, info = name+encoding.extension, variant
+
+				break
+			}
+		}
+	}
+
+	content, err := s.open(served)
+
+	if err != nil {
+		return err
+	}
+
+	defer content.Close()
+
+	etag, err := s.etag(served, info, content)
+
+	if err != nil {
+		return err
+	}
+
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	header.Set("ETag", etag)
+
+	if header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", s.cacheControl(name))
+	}
+
+	http.ServeContent(w, r, name, info.ModTime(), content)
+
+	return nil
+}
+
+type readSeekCloser interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// open opens the file, files that can not seek are read into memory.
+func (s *Server) open(name string) (readSeekCloser, error) {
+	file, err := s.fsys.Open(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if seeker, ok := file.(readSeekCloser); ok {
+		return seeker, nil
+	}
+
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nopCloser{bytes.NewReader(data)}, nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// etag hashes the content of the file, the hash is cached until the size or modification time changes.
+func (s *Server) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := fmt.Sprintf("%s:%d:%d", name, info.Size(), info.ModTime().UnixNano())
+
+	if etag, ok := s.etags.Load(key); ok {
+		return etag.(string), nil
+	}
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etag := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	s.etags.Store(key, etag)
+
+	return etag, nil
+}
+
+// cacheControl gets the Cache-Control header for the extension of the file.
+func (s *Server) cacheControl(name string) string {
+	if value, ok := s.config.CacheControl[strings.ToLower(path.Ext(name))]; ok {
+		return value
+	}
+
+	return s.config.DefaultCacheControl
+}
+
+// list writes an html listing of the directory.
+func (s *Server) list(w http.ResponseWriter, r *http.Request, name string) error {
+	entries, err := fs.ReadDir(s.fsys, name)
+
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+
+	b.WriteString("<!doctype html>\n<meta charset=\"utf-8\">\n<title>" + html.EscapeString(r.URL.Path) + "</title>\n<pre>\n")
+
+	for _, entry := range entries {
+		entryName := entry.Name()
+
+		if hidden(entryName) {
+			continue
+		}
+
+		if entry.IsDir() {
+			entryName += "/"
+		}
+
+		link := url.URL{Path: entryName}
+		b.WriteString(`<a href="` + html.EscapeString(link.String()) + `">` + html.EscapeString(entryName) + "</a>\n")
+	}
+
+	b.WriteString("</pre>\n")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", s.config.DefaultCacheControl)
+	w.Header().Set("Content-Length", strconv.Itoa(b.Len()))
+
+	if r.Method != http.MethodHead {
+		_, err = io.WriteString(w, b.String())
+	}
+
+	return err
+}
+
+// acceptsEncoding checks if the Accept-Encoding header allows the encoding.
+func acceptsEncoding(header string, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+
+		params = strings.ReplaceAll(params, " ", "")
+
+		return params != "q=0" && params != "q=0.0" && params != "q=0.00" && params != "q=0.000"
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package static_test
+
+import (
+	"github.com/volix-dev/leopard/static"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var files = fstest.MapFS{
+	"index.html":        {Data: []byte("<h1>home</h1>")},
+	"css/app.css":       {Data: []byte("body{}")},
+	"css/app.css.br":    {Data: []byte("brotli")},
+	"css/app.css.gz":    {Data: []byte("gzip")},
+	"docs/readme.txt":   {Data: []byte("read me")},
+	"docs/<script>.txt": {Data: []byte("xss")},
+	".env":              {Data: []byte("SECRET=1")},
+	"css/.hidden":       {Data: []byte("hidden")},
+
+	".well-known/acme-challenge/token": {Data: []byte("challenge")},
+}
+
+func serve(server http.Handler, method string, target string, headers ...string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, nil)
+
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestServer(t *testing.T) {
+	server := static.NewServer(files, static.Config{
+		CacheControl: map[string]string{".css": "public, max-age=31536000"},
+	})
+
+	response := serve(server, "GET", "/css/app.css")
+
+	if response.Code != 200 || response.Body.String() != "body{}" {
+		t.Fatalf("expected the file, got %d %q", response.Code, response.Body.String())
+	}
+
+	if response.Header().Get("Cache-Control") != "public, max-age=31536000" {
+		t.Errorf("expected the css cache policy, got %q", response.Header().Get("Cache-Control"))
+	}
+
+	etag := response.Header().Get("ETag")
+
+	if len(etag) != 34 {
+		t.Errorf("expected a content hash etag, got %q", etag)
+	}
+
+	if response := serve(server, "GET", "/css/app.css", "If-None-Match", etag); response.Code != 304 {
+		t.Errorf("expected 304, got %d", response.Code)
+	}
+
+	if response := serve(server, "GET", "/"); response.Body.String() != "<h1>home</h1>" || response.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("expected the index with the default cache policy, got %q %q", response.Body.String(), response.Header().Get("Cache-Control"))
+	}
+
+	if response := serve(server, "GET", "/../../index.html"); response.Body.String() != "<h1>home</h1>" {
+		t.Errorf("expected the path to be resolved inside the root, got %d", response.Code)
+	}
+
+	if response := serve(server, "GET", "/docs"); response.Code != 301 || response.Header().Get("Location") != "/docs/" {
+		t.Errorf("expected a redirect to the directory, got %d", response.Code)
+	}
+
+	if response := serve(server, "GET", "/.well-known/acme-challenge/token"); response.Body.String() != "challenge" {
+		t.Errorf("expected the .well-known directory to be served, got %d", response.Code)
+	}
+
+	for _, target := range []string{"/.env", "/css/.hidden", "/docs/", "/missing"} {
+		if response := serve(server, "GET", target); response.Code != 404 {
+			t.Errorf("expected 404 for %s, got %d", target, response.Code)
+		}
+	}
+}
+
+func TestServerOptions(t *testing.T) {
+	server := static.NewServer(files, static.Config{Browse: true, SPA: true, Precompressed: true})
+
+	response := serve(server, "GET", "/docs/")
+
+	if !strings.Contains(response.Body.String(), `<a href="readme.txt">readme.txt</a>`) ||
+		!strings.Contains(response.Body.String(), "&lt;script&gt;.txt") {
+		t.Errorf("expected an escaped listing, got %s", response.Body.String())
+	}
+
+	if response := serve(server, "GET", "/users/42"); response.Body.String() != "<h1>home</h1>" {
+		t.Errorf("expected the spa fallback, got %d %q", response.Code, response.Body.String())
+	}
+
+	if response := serve(server, "GET", "/missing.js"); response.Code != 404 {
+		t.Errorf("expected missing files with an extension to be 404, got %d", response.Code)
+	}
+
+	encodings := map[string]string{
+		"br, gzip": "brotli",
+		"gzip":     "gzip",
+		"br;q=0":   "body{}",
+		"":         "body{}",
+	}
+
+	for acceptEncoding, expected := range encodings {
+		response := serve(server, "GET", "/css/app.css", "Accept-Encoding", acceptEncoding)
+
+		if response.Body.String() != expected || response.Header().Get("Content-Type") != "text/css; charset=utf-8" {
+			t.Errorf("expected %q for %q, got %q (%s)", expected, acceptEncoding, response.Body.String(), response.Header().Get("Content-Type"))
+		}
+	}
+}
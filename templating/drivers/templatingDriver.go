@@ -13,3 +13,19 @@ type TemplatingDriver interface {
 }
 
 type Value interface{}
+
+// AssetResolver is implemented by drivers with an asset function.
+// The resolver turns the name of an asset into its url, for example a fingerprinted one.
+type AssetResolver interface {
+	SetAssetResolver(resolve func(name string) string)
+}
+
+// HelpersKey is the key of the Helpers in the data passed to RenderTemplate.
+const HelpersKey = "_helpers"
+
+// Helpers are template functions that depend on the request, like csrf_token.
+// Context.RenderTemplate passes them with the data of every template, drivers make them available as functions.
+type Helpers map[string]func(args ...Value) Value
+
+// HTML is a value that is already safe html, drivers do not escape it.
+type HTML string
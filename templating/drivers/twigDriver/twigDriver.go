@@ -1,17 +1,21 @@
 package twigDriver
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/tyler-sommer/stick"
+	"github.com/tyler-sommer/stick/twig"
 	"github.com/volix-dev/leopard/templating/drivers"
 	"io"
+	"io/fs"
 	path2 "path"
 	"reflect"
 )
 
 type TwigDriver struct {
-	env *stick.Env
+	env          *stick.Env
+	resolveAsset func(name string) string
 }
 
 func NewTwigDriver() *TwigDriver {
@@ -20,6 +24,13 @@ func NewTwigDriver() *TwigDriver {
 	}
 }
 
+// NewTwigCompatDriver creates a twig driver with the default twig filters, like escape, available.
+func NewTwigCompatDriver() *TwigDriver {
+	return &TwigDriver{
+		env: twig.New(nil),
+	}
+}
+
 func (t *TwigDriver) RenderTemplate(template string, writer io.Writer, data map[string]drivers.Value) error {
 	castedData := make(map[string]stick.Value)
 
@@ -33,6 +44,17 @@ func (t *TwigDriver) RenderTemplate(template string, writer io.Writer, data map[
 func (t *TwigDriver) Load(path string, router *mux.Router) error {
 	t.env.Loader = stick.NewFilesystemLoader(path)
 
+	return t.registerFunctions(router)
+}
+
+// LoadFS loads the templates from a fs.FS instead of a directory, for example an embed.FS.
+func (t *TwigDriver) LoadFS(fsys fs.FS, router *mux.Router) error {
+	t.env.Loader = &fsLoader{fsys: fsys}
+
+	return t.registerFunctions(router)
+}
+
+func (t *TwigDriver) registerFunctions(router *mux.Router) error {
 	t.env.Functions["route"] = func(ctx stick.Context, args ...stick.Value) stick.Value {
 		if len(args) == 0 {
 			return stick.Value("")
@@ -91,8 +113,80 @@ func (t *TwigDriver) Load(path string, router *mux.Router) error {
 		}
 
 		asset := stick.CoerceString(args[0])
+
+		if t.resolveAsset != nil {
+			return t.resolveAsset(asset)
+		}
+
 		return path2.Join("/assets/" + asset)
 	}
 
+	for _, name := range helperFunctions {
+		t.env.Functions[name] = helperFunction(name)
+	}
+
 	return nil
 }
+
+// helperFunctions are the functions that are implemented by the drivers.Helpers of the request.
+var helperFunctions = []string{"csrf_token", "csrf_field", "flashes", "old", "errors"}
+
+// helperFunction calls the helper of the request that is being rendered, it returns nothing when there is none.
+func helperFunction(name string) stick.Func {
+	return func(ctx stick.Context, args ...stick.Value) stick.Value {
+		value, _ := ctx.Scope().Get(drivers.HelpersKey)
+		helpers, _ := value.(drivers.Helpers)
+		helper, ok := helpers[name]
+
+		if !ok {
+			return ""
+		}
+
+		values := make([]drivers.Value, len(args))
+
+		for i, arg := range args {
+			values[i] = arg
+		}
+
+		result := helper(values...)
+
+		if html, ok := result.(drivers.HTML); ok {
+			return stick.NewSafeValue(string(html), "html")
+		}
+
+		return result
+	}
+}
+
+// SetAssetResolver changes the urls returned by the asset function.
+func (t *TwigDriver) SetAssetResolver(resolve func(name string) string) {
+	t.resolveAsset = resolve
+}
+
+// fsLoader loads stick templates from a fs.FS.
+type fsLoader struct {
+	fsys fs.FS
+}
+
+func (l *fsLoader) Load(name string) (stick.Template, error) {
+	data, err := fs.ReadFile(l.fsys, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &fsTemplate{name: name, data: data}, nil
+}
+
+type fsTemplate struct {
+	name string
+	data []byte
+}
+
+func (t *fsTemplate) Name() string {
+	return t.name
+}
+
+func (t *fsTemplate) Contents() io.Reader {
+	return bytes.NewReader(t.data)
+}
@@ -0,0 +1,254 @@
+package leopard
+
+import (
+	"crypto/tls"
+	"errors"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TLSConfig configures the certificates used by ServeTLS.
+// Zero values are read from the environment.
+type TLSConfig struct {
+	// CertFile is the path of the certificate, defaults to TLS_CERT_FILE.
+	CertFile string
+
+	// KeyFile is the path of the private key, defaults to TLS_KEY_FILE.
+	KeyFile string
+
+	// ReloadInterval is how often the certificate files are checked for changes, defaults to TLS_RELOAD_INTERVAL.
+	ReloadInterval time.Duration
+
+	// RedirectAddr is the address of a plaintext listener that redirects to https, defaults to TLS_REDIRECT_LISTEN.
+	// When empty no redirect listener is started.
+	RedirectAddr string
+
+	// AutocertHosts are the hosts to request certificates for using ACME (Let's Encrypt), defaults to TLS_AUTOCERT_HOSTS.
+	// When set the certificate files are not used.
+	AutocertHosts []string
+
+	// AutocertCacheDir is the directory the ACME certificates are stored in, defaults to TLS_AUTOCERT_CACHE.
+	AutocertCacheDir string
+}
+
+// fillFromEnv sets all the zero values to the values from the environment.
+func (c *TLSConfig) fillFromEnv() error {
+	if c.CertFile == "" {
+		c.CertFile = EnvSettingD("TLS_CERT_FILE", "").GetValue().(string)
+	}
+
+	if c.KeyFile == "" {
+		c.KeyFile = EnvSettingD("TLS_KEY_FILE", "").GetValue().(string)
+	}
+
+	if c.RedirectAddr == "" {
+		c.RedirectAddr = EnvSettingD("TLS_REDIRECT_LISTEN", "").GetValue().(string)
+	}
+
+	if c.AutocertHosts == nil {
+		hosts := EnvSettingD("TLS_AUTOCERT_HOSTS", "").GetValue().(string)
+
+		for _, host := range strings.Split(hosts, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				c.AutocertHosts = append(c.AutocertHosts, host)
+			}
+		}
+	}
+
+	if c.AutocertCacheDir == "" {
+		c.AutocertCacheDir = EnvSettingD("TLS_AUTOCERT_CACHE", "./certs").GetValue().(string)
+	}
+
+	if c.ReloadInterval == 0 {
+		interval, err := envDuration("TLS_RELOAD_INTERVAL", "10s")
+
+		if err != nil {
+			return err
+		}
+
+		c.ReloadInterval = interval
+	}
+
+	return nil
+}
+
+// ServeTLS starts the server with TLS and HTTP/2 enabled.
+// The certificate is either loaded from the configured files, and reloaded when they change,
+// or requested automatically when autocert hosts are configured.
+// Should only be called once.
+//
+// Like Serve, it shuts down gracefully when an interrupt or terminate signal is received.
+func (a *LeopardApp) ServeTLS() error {
+	a.server = NewSimpleServer(a.ListenAddr, a.Server)
+	a.server.Handler = a
+
+	var redirect http.Handler = http.HandlerFunc(a.redirectToHTTPS)
+
+	if len(a.TLS.AutocertHosts) > 0 {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(a.TLS.AutocertHosts...),
+			Cache:      autocert.DirCache(a.TLS.AutocertCacheDir),
+		}
+
+		a.server.TLSConfig = &tls.Config{
+			GetCertificate: manager.GetCertificate,
+			NextProtos:     []string{"h2", "http/1.1", acme.ALPNProto},
+			MinVersion:     tls.VersionTLS12,
+		}
+
+		// The redirect listener also answers the http-01 challenges.
+		redirect = manager.HTTPHandler(redirect)
+	} else {
+		if a.TLS.CertFile == "" || a.TLS.KeyFile == "" {
+			return errors.New("TLS_CERT_FILE and TLS_KEY_FILE are required to serve with TLS")
+		}
+
+		reloader, err := newCertReloader(a.TLS.CertFile, a.TLS.KeyFile, a.TLS.ReloadInterval)
+
+		if err != nil {
+			return err
+		}
+
+		reloader.logger = a.logger()
+
+		a.server.TLSConfig = &tls.Config{
+			GetCertificate: reloader.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		}
+	}
+
+	listeners := []func() error{
+		func() error {
+			return a.server.ListenAndServeTLS("", "")
+		},
+	}
+
+	if a.TLS.RedirectAddr != "" {
+		a.redirectServer = NewSimpleServer(a.TLS.RedirectAddr, a.Server)
+		a.redirectServer.Handler = redirect
+
+		listeners = append(listeners, a.redirectServer.ListenAndServe)
+	}
+
+	return a.serve(listeners...)
+}
+
+// redirectToHTTPS permanently redirects the request to the same url on the TLS listener.
+func (a *LeopardApp) redirectToHTTPS(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if _, port, err := net.SplitHostPort(a.ListenAddr); err == nil && port != "" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	target := "https://" + host + r.URL.RequestURI()
+
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
+
+// certReloader loads a certificate from disk and reloads it when the files change.
+// The files are checked at most once per interval, during a handshake.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	logger   LoggerInterface
+
+	lock        sync.RWMutex
+	cert        *tls.Certificate
+	modTime     time.Time
+	lastChecked time.Time
+}
+
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: interval,
+		logger:   Logger,
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, it is used as tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.lock.RLock()
+	cert := r.cert
+	check := r.interval > 0 && time.Since(r.lastChecked) > r.interval
+	r.lock.RUnlock()
+
+	if check {
+		if err := r.reload(); err != nil {
+			r.logger.Error("failed to reload the TLS certificate, keeping the old one: ", err)
+		}
+
+		r.lock.RLock()
+		cert = r.cert
+		r.lock.RUnlock()
+	}
+
+	return cert, nil
+}
+
+// reload loads the certificate if the files changed since the last load.
+func (r *certReloader) reload() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.lastChecked = time.Now()
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+
+	if err != nil {
+		return err
+	}
+
+	if r.cert != nil && !modTime.After(r.modTime) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	if err != nil {
+		return err
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+
+	return nil
+}
+
+// latestModTime gets the most recent modification time of the files.
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if stat.ModTime().After(latest) {
+			latest = stat.ModTime()
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,113 @@
+package leopard
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func writeTestCertificate(t *testing.T, dir string, commonName string, modTime time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := path.Join(dir, "cert.pem"), path.Join(dir, "key.pem")
+
+	for file, block := range map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDer},
+	} {
+		if err := os.WriteFile(file, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return certFile, keyFile
+}
+
+func commonName(t *testing.T, reloader *certReloader) string {
+	cert, err := reloader.GetCertificate(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed.Subject.CommonName
+}
+
+func TestCertReloaderReloadsChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "first", time.Now().Add(-time.Minute))
+
+	reloader, err := newCertReloader(certFile, keyFile, time.Nanosecond)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := commonName(t, reloader); name != "first" {
+		t.Fatalf("expected the first certificate, got %s", name)
+	}
+
+	writeTestCertificate(t, dir, "second", time.Now())
+
+	if name := commonName(t, reloader); name != "second" {
+		t.Fatalf("expected the reloaded certificate, got %s", name)
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	a := &LeopardApp{Options: &Options{ListenAddr: ":8443"}}
+
+	w := httptest.NewRecorder()
+	a.redirectToHTTPS(w, httptest.NewRequest(http.MethodGet, "http://example.com/users?page=2", nil))
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected a permanent redirect, got %d", w.Code)
+	}
+
+	if location := w.Header().Get("Location"); location != "https://example.com:8443/users?page=2" {
+		t.Fatalf("unexpected redirect location %s", location)
+	}
+}
@@ -0,0 +1,236 @@
+package leopard
+
+import (
+	"fmt"
+	"github.com/volix-dev/leopard/files"
+	"github.com/volix-dev/leopard/helpers"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// UploadConfig limits the files that can be uploaded.
+// Zero values are read from the environment.
+type UploadConfig struct {
+	// MaxFileSize is the maximum size of a single file in bytes, defaults to UPLOAD_MAX_FILE_SIZE.
+	// The size of the whole request is limited by the MaxBodySize of the BindConfig.
+	MaxFileSize int64
+
+	// MaxFiles is the maximum number of files in a request, defaults to UPLOAD_MAX_FILES.
+	MaxFiles int
+
+	// AllowedTypes are the MIME types that can be uploaded, defaults to the comma separated UPLOAD_ALLOWED_TYPES.
+	// Wildcards like image/* are supported, all types are allowed when it is empty.
+	// The type is sniffed from the content, the type sent by the client is ignored.
+	AllowedTypes []string
+}
+
+// fillFromEnv sets all the zero values to the values from the environment.
+func (c *UploadConfig) fillFromEnv() error {
+	if c.MaxFileSize == 0 {
+		size, err := envInt("UPLOAD_MAX_FILE_SIZE", 10<<20)
+
+		if err != nil {
+			return err
+		}
+
+		c.MaxFileSize = int64(size)
+	}
+
+	if c.MaxFiles == 0 {
+		count, err := envInt("UPLOAD_MAX_FILES", 10)
+
+		if err != nil {
+			return err
+		}
+
+		c.MaxFiles = count
+	}
+
+	if c.AllowedTypes == nil {
+		for _, t := range strings.Split(EnvSettingD("UPLOAD_ALLOWED_TYPES", "").GetValue().(string), ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				c.AllowedTypes = append(c.AllowedTypes, t)
+			}
+		}
+	}
+
+	return nil
+}
+
+// uploadConfig gets the upload config of the app, or the defaults when the app has no options.
+func (a *LeopardApp) uploadConfig() UploadConfig {
+	if a == nil || a.Options == nil || a.Uploads.MaxFileSize == 0 {
+		return UploadConfig{MaxFileSize: 10 << 20, MaxFiles: 10}
+	}
+
+	return a.Uploads
+}
+
+// Upload is a file uploaded in a multipart form.
+type Upload struct {
+	// Filename is the name of the file on the client.
+	// It is not safe to use it as a path, SaveTo generates a random name.
+	Filename string
+
+	// Size is the size of the file in bytes.
+	Size int64
+
+	// ContentType is the MIME type sniffed from the first bytes of the file.
+	ContentType string
+
+	header *multipart.FileHeader
+}
+
+// newUpload sniffs the type of the file.
+func newUpload(header *multipart.FileHeader) (*Upload, error) {
+	file, err := header.Open()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	buffer := make([]byte, 512)
+	n, err := io.ReadFull(file, buffer)
+
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	return &Upload{
+		Filename:    header.Filename,
+		Size:        header.Size,
+		ContentType: http.DetectContentType(buffer[:n]),
+		header:      header,
+	}, nil
+}
+
+// Open opens the uploaded file for reading.
+func (u *Upload) Open() (multipart.File, error) {
+	return u.header.Open()
+}
+
+// Extension gets the lowercase extension for the sniffed content type, including the dot.
+// The extension of the client's filename is only used when it belongs to the content type,
+// so a file can not be stored as a type it is not, like a png named x.html.
+func (u *Upload) Extension() string {
+	extensions, err := mime.ExtensionsByType(u.ContentType)
+
+	if err != nil || len(extensions) == 0 {
+		return ""
+	}
+
+	ext := strings.ToLower(path.Ext(u.Filename))
+
+	for _, e := range extensions {
+		if e == ext {
+			return ext
+		}
+	}
+
+	return extensions[0]
+}
+
+// SaveTo stores the file in the directory of the driver with a random name and returns the path of the file.
+// The file is streamed to the driver, it is never read into memory as a whole.
+func (u *Upload) SaveTo(driver files.Driver, dir string) (string, error) {
+	name := strings.ToLower(helpers.NewULID()) + u.Extension()
+	target := path.Join(dir, name)
+
+	return target, u.SaveAs(driver, target)
+}
+
+// SaveAs stores the file in the driver at the path.
+// Never use the filename of the client in the path, use SaveTo instead.
+func (u *Upload) SaveAs(driver files.Driver, path string) error {
+	file, err := u.Open()
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	return driver.WriteFileStream(path, file)
+}
+
+// allowed checks if the content type matches one of the allowed types.
+func (u *Upload) allowed(types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+
+	contentType, _, _ := mime.ParseMediaType(u.ContentType)
+
+	for _, t := range types {
+		if t == contentType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// File gets the uploaded file of the form field.
+// The limits of the UploadConfig are checked, the errors respond with a 4xx status code.
+func (c *Context) File(name string) (*Upload, error) {
+	uploads, err := c.Files(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return uploads[0], nil
+}
+
+// Files gets all the uploaded files of the form field.
+func (c *Context) Files(name string) ([]*Upload, error) {
+	config := c.a.uploadConfig()
+
+	if err := c.parseForm(c.a.bindConfig()); err != nil {
+		return nil, err
+	}
+
+	if c.request.MultipartForm == nil || len(c.request.MultipartForm.File[name]) == 0 {
+		return nil, &ParamError{Source: "form", Name: name}
+	}
+
+	count := 0
+
+	for _, headers := range c.request.MultipartForm.File {
+		count += len(headers)
+	}
+
+	if config.MaxFiles > 0 && count > config.MaxFiles {
+		return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("at most %d files can be uploaded", config.MaxFiles))
+	}
+
+	var uploads []*Upload
+
+	for _, header := range c.request.MultipartForm.File[name] {
+		if config.MaxFileSize > 0 && header.Size > config.MaxFileSize {
+			return nil, NewHTTPError(http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("file %q is larger than %d bytes", header.Filename, config.MaxFileSize))
+		}
+
+		upload, err := newUpload(header)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !upload.allowed(config.AllowedTypes) {
+			return nil, NewHTTPError(http.StatusUnsupportedMediaType,
+				fmt.Sprintf("file %q has a type that is not allowed: %s", header.Filename, upload.ContentType))
+		}
+
+		uploads = append(uploads, upload)
+	}
+
+	return uploads, nil
+}
@@ -0,0 +1,131 @@
+package leopard_test
+
+import (
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func uploadApp(t *testing.T, config leopard.UploadConfig) *leopardtest.App {
+	a := leopardtest.New(t, leopard.WithUploadConfig(config))
+
+	a.POST("/avatar", func(c leopard.ContextInterface) error {
+		upload, err := c.File("avatar")
+
+		if err != nil {
+			return err
+		}
+
+		path, err := upload.SaveTo(c.App().FileDriver, "avatars")
+
+		if err != nil {
+			return err
+		}
+
+		return c.Json(map[string]interface{}{
+			"path":     path,
+			"filename": upload.Filename,
+			"size":     upload.Size,
+			"type":     upload.ContentType,
+		})
+	})
+
+	return a
+}
+
+func TestUpload(t *testing.T) {
+	a := uploadApp(t, leopard.UploadConfig{MaxFileSize: 1024, MaxFiles: 1, AllowedTypes: []string{"image/*"}})
+
+	var response struct {
+		Path string `json:"path"`
+	}
+
+	a.Test().POST("/avatar").
+		WithMultipart(url.Values{"name": {"Ada"}}, leopardtest.FormFile{Field: "avatar", Filename: "../../Me.PNG", Content: png}).
+		Expect(t).
+		Status(200).
+		JSONPath("filename", "Me.PNG").
+		JSONPath("size", len(png)).
+		JSONPath("type", "image/png").
+		JSON(&response)
+
+	if !strings.HasPrefix(response.Path, "avatars/") || !strings.HasSuffix(response.Path, ".png") || strings.Contains(response.Path, "..") {
+		t.Errorf("expected a random name in the avatars directory, got %q", response.Path)
+	}
+
+	a.AssertFile(t, response.Path, png)
+
+	// The extension follows the content, not the name the client picked.
+	a.Test().POST("/avatar").
+		WithMultipart(nil, leopardtest.FormFile{Field: "avatar", Filename: "x.html", Content: png}).
+		Expect(t).
+		Status(200).
+		JSON(&response)
+
+	if !strings.HasSuffix(response.Path, ".png") {
+		t.Errorf("expected a png to be saved as .png, got %q", response.Path)
+	}
+}
+
+func TestUploadLimits(t *testing.T) {
+	a := uploadApp(t, leopard.UploadConfig{MaxFileSize: 16, MaxFiles: 1, AllowedTypes: []string{"image/png"}})
+	client := a.Test()
+
+	client.POST("/avatar").
+		WithMultipart(nil).
+		Expect(t).
+		Status(400).
+		JSONPath("message", `missing form parameter "avatar"`)
+
+	client.POST("/avatar").
+		WithMultipart(nil, leopardtest.FormFile{Field: "avatar", Filename: "big.png", Content: append(png, make([]byte, 32)...)}).
+		Expect(t).
+		Status(413)
+
+	client.POST("/avatar").
+		WithMultipart(nil, leopardtest.FormFile{Field: "avatar", Filename: "evil.png", Content: []byte("<html><script>")}).
+		Expect(t).
+		Status(415)
+
+	client.POST("/avatar").
+		WithMultipart(nil,
+			leopardtest.FormFile{Field: "avatar", Filename: "a.png", Content: png},
+			leopardtest.FormFile{Field: "avatar", Filename: "b.png", Content: png},
+		).
+		Expect(t).
+		Status(400).
+		JSONPath("message", "at most 1 files can be uploaded")
+}
+
+func TestUploadTemporaryFiles(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	a := leopardtest.New(t, leopard.WithBindConfig(leopard.BindConfig{MaxBodySize: 1024, MaxMemory: 1}))
+
+	a.POST("/avatar", func(c leopard.ContextInterface) error {
+		_, err := c.File("avatar")
+
+		return err
+	})
+
+	a.Test().POST("/avatar").
+		WithMultipart(nil, leopardtest.FormFile{Field: "avatar", Filename: "me.png", Content: png}).
+		Expect(t).
+		Status(200)
+
+	entries, err := os.ReadDir(tmp)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Errorf("expected the temporary files of the upload to be removed, found %d", len(entries))
+	}
+}
@@ -0,0 +1,44 @@
+package leopard
+
+import "context"
+
+// User is an authenticated user, see the auth package for the guards that authenticate requests.
+type User interface {
+	// AuthID gets the unique ID of the user, it is kept in the session to find the user again.
+	AuthID() string
+}
+
+type userKey struct{}
+
+// ContextWithUser returns a context carrying the authenticated user.
+func ContextWithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext gets the authenticated user from the context, or nil when there is none.
+func UserFromContext(ctx context.Context) User {
+	user, _ := ctx.Value(userKey{}).(User)
+
+	return user
+}
+
+// User gets the authenticated user of the request, or nil when the request is not authenticated.
+func (c *Context) User() User {
+	return UserFromContext(c.request.Context())
+}
+
+// IsAuthenticated checks if the request has an authenticated user.
+func (c *Context) IsAuthenticated() bool {
+	return c.User() != nil
+}
+
+// SetUser sets the authenticated user of the request, nil removes it.
+// The ID of the user is added to the log fields of the request as user_id.
+// It does not log the user in for later requests, use the auth package for that.
+func (c *Context) SetUser(user User) {
+	c.request = c.request.WithContext(ContextWithUser(c.request.Context(), user))
+
+	if user != nil {
+		c.AddLogFields(Fields{"user_id": user.AuthID()})
+	}
+}
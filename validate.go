@@ -0,0 +1,67 @@
+package leopard
+
+import (
+	"errors"
+	"github.com/volix-dev/leopard/validation"
+	"net/http"
+)
+
+// ValidationError is returned by BindAndValidate when the request fails validation.
+// It responds with a 422 Unprocessable Entity that lists the fields and their messages.
+type ValidationError struct {
+	Errors validation.Errors
+}
+
+func (e *ValidationError) Error() string {
+	return "validation failed: " + e.Errors.Error()
+}
+
+// StatusCode makes the error handler respond with a 422.
+func (e *ValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// Unwrap makes the error match ErrValidation.
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// ErrorDetails returns the fields that failed.
+func (e *ValidationError) ErrorDetails() interface{} {
+	return e.Errors
+}
+
+// Validate validates the struct with the validator of the app.
+// A ValidationError is returned when one or more fields fail.
+func (c *Context) Validate(dst interface{}) error {
+	validator := validation.Default
+
+	if c.a != nil && c.a.Validator != nil {
+		validator = c.a.Validator
+	}
+
+	err := validator.Validate(dst)
+
+	var errs validation.Errors
+
+	if errors.As(err, &errs) {
+		return &ValidationError{Errors: errs}
+	}
+
+	return err
+}
+
+// BindAndValidate binds the request to the struct and validates it.
+//
+//	var user CreateUser
+//
+//	if err := c.BindAndValidate(&user); err != nil {
+//		return err
+//	}
+func (c *Context) BindAndValidate(dst interface{}) error {
+	if err := c.Bind(dst); err != nil {
+		return err
+	}
+
+	return c.Validate(dst)
+}
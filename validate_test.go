@@ -0,0 +1,42 @@
+package leopard_test
+
+import (
+	"github.com/volix-dev/leopard"
+	"github.com/volix-dev/leopard/leopardtest"
+	"testing"
+)
+
+func TestBindAndValidate(t *testing.T) {
+	a := leopardtest.New(t)
+
+	type signup struct {
+		Email string `json:"email" validate:"required,email"`
+		Name  string `json:"name" validate:"required,min=3"`
+	}
+
+	a.POST("/signup", func(c leopard.ContextInterface) error {
+		var form signup
+
+		if err := c.BindAndValidate(&form); err != nil {
+			return err
+		}
+
+		return c.Json(form)
+	})
+
+	client := a.Test()
+
+	client.POST("/signup").
+		WithJSON(map[string]string{"email": "ada@example.com", "name": "Ada"}).
+		Expect(t).
+		Status(200).
+		JSONPath("name", "Ada")
+
+	client.POST("/signup").
+		WithJSON(map[string]string{"email": "ada", "name": ""}).
+		Expect(t).
+		Status(422).
+		JSONPath("errors.0.field", "email").
+		JSONPath("errors.0.rule", "email").
+		JSONPath("errors.1.message", "name is required")
+}
@@ -0,0 +1,161 @@
+package validation
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMessages are the english messages of the built-in rules.
+var DefaultMessages = Messages{
+	"required":     "{field} is required",
+	"email":        "{field} must be a valid email address",
+	"url":          "{field} must be a valid url",
+	"uuid":         "{field} must be a valid UUID",
+	"min":          "{field} must be at least {param}",
+	"min.string":   "{field} must be at least {param} characters",
+	"min.list":     "{field} must contain at least {param} items",
+	"max":          "{field} must be at most {param}",
+	"max.string":   "{field} must be at most {param} characters",
+	"max.list":     "{field} must contain at most {param} items",
+	"len":          "{field} must be {param}",
+	"len.string":   "{field} must be exactly {param} characters",
+	"len.list":     "{field} must contain exactly {param} items",
+	"oneof":        "{field} must be one of: {param}",
+	"numeric":      "{field} must be a number",
+	"alpha":        "{field} may only contain letters",
+	"alphanumeric": "{field} may only contain letters and numbers",
+}
+
+var builtinRules = map[string]RuleFunc{
+	"required":     func(value reflect.Value, param string) bool { return true },
+	"email":        isEmail,
+	"url":          isURL,
+	"uuid":         matches(regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)),
+	"min":          compare(func(size, limit float64) bool { return size >= limit }),
+	"max":          compare(func(size, limit float64) bool { return size <= limit }),
+	"len":          compare(func(size, limit float64) bool { return size == limit }),
+	"oneof":        oneOf,
+	"numeric":      isNumeric,
+	"alpha":        onlyRunes(unicode.IsLetter),
+	"alphanumeric": onlyRunes(func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }),
+}
+
+func isEmail(value reflect.Value, param string) bool {
+	if value.Kind() != reflect.String {
+		return false
+	}
+
+	address, err := mail.ParseAddress(value.String())
+
+	// ParseAddress also accepts names, like "Ada <ada@example.com>".
+	return err == nil && address.Address == value.String()
+}
+
+func isURL(value reflect.Value, param string) bool {
+	if value.Kind() != reflect.String {
+		return false
+	}
+
+	u, err := url.ParseRequestURI(value.String())
+
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func isNumeric(value reflect.Value, param string) bool {
+	if kindOf(value) == "number" {
+		return true
+	}
+
+	_, err := strconv.ParseFloat(value.String(), 64)
+
+	return value.Kind() == reflect.String && err == nil
+}
+
+func matches(pattern *regexp.Regexp) RuleFunc {
+	return func(value reflect.Value, param string) bool {
+		return value.Kind() == reflect.String && pattern.MatchString(value.String())
+	}
+}
+
+func onlyRunes(allowed func(r rune) bool) RuleFunc {
+	return func(value reflect.Value, param string) bool {
+		if value.Kind() != reflect.String {
+			return false
+		}
+
+		for _, r := range value.String() {
+			if !allowed(r) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// compare checks the size of the value against the param.
+// The size is the number of characters of strings, the length of lists and the value of numbers.
+func compare(check func(size, limit float64) bool) RuleFunc {
+	return func(value reflect.Value, param string) bool {
+		limit, err := strconv.ParseFloat(param, 64)
+
+		if err != nil {
+			return false
+		}
+
+		var size float64
+
+		switch kindOf(value) {
+		case "string":
+			size = float64(utf8.RuneCountInString(value.String()))
+		case "list":
+			size = float64(value.Len())
+		case "number":
+			size = toFloat(value)
+		default:
+			return false
+		}
+
+		return check(size, limit)
+	}
+}
+
+// oneOf checks if the value is one of the space separated options.
+func oneOf(value reflect.Value, param string) bool {
+	var s string
+
+	switch kindOf(value) {
+	case "string":
+		s = value.String()
+	case "number":
+		s = strconv.FormatFloat(toFloat(value), 'f', -1, 64)
+	default:
+		return false
+	}
+
+	for _, option := range strings.Fields(param) {
+		if s == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toFloat converts a number of any kind to a float.
+func toFloat(value reflect.Value) float64 {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	}
+
+	return value.Float()
+}
@@ -0,0 +1,345 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RuleFunc checks a value, param is the text after the = in the tag, for example "3" in min=3.
+// Pointers are dereferenced before the rule is called.
+type RuleFunc func(value reflect.Value, param string) bool
+
+// Messages maps rules to message templates, {field} and {param} are replaced in the templates.
+// A rule can have a message per kind of value with the keys rule.string, rule.number and rule.list,
+// for example "min.string", these are used before the message of the rule itself.
+type Messages map[string]string
+
+// FieldError describes a field that failed a rule.
+type FieldError struct {
+	// Field is the path of the field, using the json names, for example "items.0.name".
+	Field string `json:"field"`
+
+	// Rule is the rule that failed.
+	Rule string `json:"rule"`
+
+	// Param is the parameter of the rule, if any.
+	Param string `json:"param,omitempty"`
+
+	// Message describes the error for users.
+	Message string `json:"message"`
+
+	kind string
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// Errors are all the fields that failed validation.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+
+	return strings.Join(messages, ", ")
+}
+
+// Localize returns a copy of the errors with the messages rendered from other templates.
+// Rules that are missing from the messages keep their message.
+func (e Errors) Localize(messages Messages) Errors {
+	localized := make(Errors, len(e))
+
+	for i, err := range e {
+		localized[i] = err
+
+		if message, ok := messages.find(err.Rule, err.kind); ok {
+			localized[i].Message = render(message, err)
+		}
+	}
+
+	return localized
+}
+
+// Validator validates structs using the validate tag.
+//
+//	type User struct {
+//		Name  string `json:"name" validate:"required,min=3,max=64"`
+//		Email string `json:"email" validate:"required,email"`
+//		Role  string `json:"role" validate:"oneof=admin user"`
+//	}
+//
+// Empty strings, lists and nil pointers are only checked by required, the other rules are skipped.
+// Nested structs and slices of structs are validated as well.
+type Validator struct {
+	rules    map[string]RuleFunc
+	messages Messages
+	lock     sync.RWMutex
+}
+
+// New creates a validator with the built-in rules and english messages.
+func New() *Validator {
+	v := &Validator{
+		rules:    map[string]RuleFunc{},
+		messages: Messages{},
+	}
+
+	for name, rule := range builtinRules {
+		v.rules[name] = rule
+	}
+
+	for rule, message := range DefaultMessages {
+		v.messages[rule] = message
+	}
+
+	return v
+}
+
+// Default is the validator used by the package level functions.
+var Default = New()
+
+// RegisterRule registers a rule on the default validator.
+func RegisterRule(name string, rule RuleFunc, message string) {
+	Default.RegisterRule(name, rule, message)
+}
+
+// Validate validates the struct with the default validator.
+func Validate(s interface{}) error {
+	return Default.Validate(s)
+}
+
+// RegisterRule adds a rule, or replaces a built-in one, with the message shown when it fails.
+func (v *Validator) RegisterRule(name string, rule RuleFunc, message string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	v.rules[name] = rule
+	v.messages[name] = message
+}
+
+// SetMessages replaces the messages of the rules, for example to translate them.
+// Rules that are missing from the messages keep their message.
+func (v *Validator) SetMessages(messages Messages) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	for rule, message := range messages {
+		v.messages[rule] = message
+	}
+}
+
+// Validate checks all the fields of the struct, it returns Errors when one or more fields fail.
+func (v *Validator) Validate(s interface{}) error {
+	value := reflect.ValueOf(s)
+
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("validation: expected a struct, got %T", s)
+	}
+
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
+	var errs Errors
+
+	if err := v.validateStruct(value, "", &errs); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateStruct checks the fields of the struct, the names of the fields are prefixed with the path.
+func (v *Validator) validateStruct(value reflect.Value, path string, errs *Errors) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := path + fieldName(field)
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			name = strings.TrimSuffix(path, ".")
+		}
+
+		tag := field.Tag.Get("validate")
+
+		if tag == "-" {
+			continue
+		}
+
+		if tag != "" {
+			if err := v.validateField(value.Field(i), name, tag, errs); err != nil {
+				return err
+			}
+		}
+
+		if err := v.validateNested(value.Field(i), name, errs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateNested validates structs and slices of structs inside a field.
+func (v *Validator) validateNested(value reflect.Value, path string, errs *Errors) error {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	prefix := path
+
+	if prefix != "" {
+		prefix += "."
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return v.validateStruct(value, prefix, errs)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.validateNested(value.Index(i), prefix+strconv.Itoa(i), errs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateField runs the rules of the tag on the value.
+func (v *Validator) validateField(value reflect.Value, name string, tag string, errs *Errors) error {
+	rules := strings.Split(tag, ",")
+	empty := isEmpty(value)
+
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	// Optional values that are not set are not checked, zero numbers are.
+	skip := empty && (value.Kind() == reflect.Pointer || kindOf(value) == "string" || kindOf(value) == "list")
+
+	for _, rule := range rules {
+		rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		if rule == "" || (skip && rule != "required") {
+			continue
+		}
+
+		check, ok := v.rules[rule]
+
+		if !ok {
+			return fmt.Errorf("validation: unknown rule %q on field %s", rule, name)
+		}
+
+		if rule == "required" {
+			if empty {
+				*errs = append(*errs, v.newError(name, rule, param, value))
+
+				return nil
+			}
+
+			continue
+		}
+
+		if !check(value, param) {
+			*errs = append(*errs, v.newError(name, rule, param, value))
+		}
+	}
+
+	return nil
+}
+
+func (v *Validator) newError(name string, rule string, param string, value reflect.Value) FieldError {
+	err := FieldError{
+		Field: name,
+		Rule:  rule,
+		Param: param,
+		kind:  kindOf(value),
+	}
+
+	message, ok := v.messages.find(rule, err.kind)
+
+	if !ok {
+		message = "{field} is invalid"
+	}
+
+	err.Message = render(message, err)
+
+	return err
+}
+
+// find gets the message for the kind of value, or the message of the rule.
+func (m Messages) find(rule string, kind string) (string, bool) {
+	if message, ok := m[rule+"."+kind]; ok {
+		return message, true
+	}
+
+	message, ok := m[rule]
+
+	return message, ok
+}
+
+func render(message string, err FieldError) string {
+	return strings.NewReplacer("{field}", err.Field, "{param}", err.Param).Replace(message)
+}
+
+// fieldName gets the name of the field in json, the go name is used when there is no json tag.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+// isEmpty checks if the value is the zero value, or an empty slice or map.
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	case reflect.Invalid:
+		return true
+	}
+
+	return value.IsZero()
+}
+
+// kindOf groups the kinds of values for messages.
+func kindOf(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "list"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+
+	return "value"
+}
@@ -0,0 +1,135 @@
+package validation_test
+
+import (
+	"errors"
+	"github.com/volix-dev/leopard/validation"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type item struct {
+	Name     string `json:"name" validate:"required,max=8"`
+	Quantity int    `json:"quantity" validate:"min=1,max=10"`
+}
+
+type order struct {
+	Email    string   `json:"email" validate:"required,email"`
+	Name     string   `json:"name" validate:"min=3,alpha"`
+	Status   string   `json:"status" validate:"oneof=new paid"`
+	Website  string   `json:"website" validate:"url"`
+	Tags     []string `json:"tags" validate:"max=2"`
+	Address  *address `json:"address"`
+	Items    []item   `json:"items" validate:"required"`
+	Optional string   `json:"optional" validate:"email"`
+}
+
+func fieldErrors(t *testing.T, err error) map[string][]string {
+	t.Helper()
+
+	var errs validation.Errors
+
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+
+	fields := map[string][]string{}
+
+	for _, e := range errs {
+		fields[e.Field] = append(fields[e.Field], e.Message)
+	}
+
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	valid := order{
+		Email:   "ada@example.com",
+		Name:    "Ada",
+		Status:  "paid",
+		Website: "https://example.com",
+		Address: &address{City: "London"},
+		Items:   []item{{Name: "book", Quantity: 2}},
+	}
+
+	if err := validation.Validate(valid); err != nil {
+		t.Fatalf("expected no errors, got %v", err)
+	}
+
+	invalid := order{
+		Email:   "Ada <ada@example.com>",
+		Name:    "A1",
+		Status:  "lost",
+		Website: "example.com",
+		Tags:    []string{"a", "b", "c"},
+		Address: &address{},
+		Items:   []item{{Name: "encyclopedia", Quantity: 0}},
+	}
+
+	expected := map[string][]string{
+		"email":            {"email must be a valid email address"},
+		"name":             {"name must be at least 3 characters", "name may only contain letters"},
+		"status":           {"status must be one of: new paid"},
+		"website":          {"website must be a valid url"},
+		"tags":             {"tags must contain at most 2 items"},
+		"address.city":     {"address.city is required"},
+		"items.0.name":     {"items.0.name must be at most 8 characters"},
+		"items.0.quantity": {"items.0.quantity must be at least 1"},
+	}
+
+	fields := fieldErrors(t, validation.Validate(&invalid))
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+
+	fields = fieldErrors(t, validation.Validate(order{}))
+	expected = map[string][]string{"email": {"email is required"}, "items": {"items is required"}}
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected only the required fields to fail, got %v", fields)
+	}
+}
+
+func TestCustomRulesAndMessages(t *testing.T) {
+	v := validation.New()
+
+	v.RegisterRule("even", func(value reflect.Value, param string) bool {
+		return value.Int()%2 == 0
+	}, "{field} must be even")
+
+	v.SetMessages(validation.Messages{"required": "{field} is verplicht"})
+
+	type form struct {
+		Number int    `json:"number" validate:"even"`
+		Name   string `json:"name" validate:"required"`
+	}
+
+	fields := fieldErrors(t, v.Validate(form{Number: 3}))
+	expected := map[string][]string{"number": {"number must be even"}, "name": {"name is verplicht"}}
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("unexpected messages %v", fields)
+	}
+
+	var errs validation.Errors
+	errors.As(v.Validate(form{Number: 2}), &errs)
+
+	localized := errs.Localize(validation.Messages{"required": "{field} est obligatoire"})
+
+	if localized[0].Message != "name est obligatoire" || errs[0].Message != "name is verplicht" {
+		t.Errorf("unexpected localized messages %v", localized)
+	}
+
+	type unknown struct {
+		Name string `validate:"shiny"`
+	}
+
+	if err := v.Validate(unknown{Name: "x"}); err == nil || !strings.Contains(err.Error(), "unknown rule") {
+		t.Errorf("expected an unknown rule error, got %v", err)
+	}
+}